@@ -0,0 +1,335 @@
+package attack
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+// CiphertextOnlyOptions control CiphertextOnly
+type CiphertextOnlyOptions struct {
+	Options
+
+	// rotor types to build wheel orders from, nil uses I to V
+	Rotors []string
+
+	// explicit wheel orders in machine order (rightmost first), overrides Rotors
+	WheelOrders [][]string
+
+	// reflector type, "" uses UKW-B
+	Reflector string
+
+	// number of the best start positions refined by hill climbing, 0 means 10
+	Candidates int
+
+	// largest number of plugboard pairs tried, 0 means 10
+	MaxPlugs int
+
+	// rates decryptions while the plugboard is climbed, nil uses German bigrams
+	Scorer scoring.Scorer
+}
+
+// CiphertextOnly recovers keys from a ciphertext alone, the way Gillogly and
+// Weierud-Sullivan attacked Enigma messages:
+//
+//  1. every wheel order and start position is tried with ring settings AAA and an empty
+//     plugboard, scored by the index of coincidence of the decryption
+//  2. the ring settings of the two right rotors of the best candidates are searched by
+//     n-gram statistics, scored by the same scorer as the plugboard
+//  3. plugboard pairs are added by hill climbing on CiphertextOnlyOptions.Scorer, German
+//     bigram statistics by default
+//
+// The candidates are returned best first, scored by CiphertextOnlyOptions.Scorer. The
+// ciphertext should be a few hundred letters long, the more plugboard pairs the longer. If
+// ctx is cancelled the search stops early and returns the candidates finished so far with
+// the context error; when that happens during step 1 they are not refined yet and scored
+// by index of coincidence.
+func CiphertextOnly(ctx context.Context, ciphertext string, opts CiphertextOnlyOptions) ([]Result, error) {
+	text := Letters(ciphertext)
+	if len(text) < 2 {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	reflector := opts.Reflector
+	if reflector == "" {
+		reflector = "UKW-B"
+	}
+	candidates := opts.Candidates
+	if candidates <= 0 {
+		candidates = 10
+	}
+	maxPlugs := opts.MaxPlugs
+	if maxPlugs <= 0 {
+		maxPlugs = 10
+	}
+	if maxPlugs > enigma.AlphabetSize/2 {
+		return nil, fmt.Errorf("at most %d plugboard pairs are possible", enigma.AlphabetSize/2)
+	}
+	scorer := opts.Scorer
+	if scorer == nil {
+		bigrams, err := scoring.German(2)
+		if err != nil {
+			return nil, err
+		}
+		scorer = bigrams
+	}
+
+	wheelOrders := opts.WheelOrders
+	if wheelOrders == nil {
+		rotors := opts.Rotors
+		if rotors == nil {
+			rotors = []string{"I", "II", "III", "IV", "V"}
+		}
+		wheelOrders = WheelOrders(rotors, 3)
+	}
+
+	configs := make([]enigma.Config, len(wheelOrders))
+	for i, order := range wheelOrders {
+		cfg, err := enigma.NewConfig(order, reflector, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		configs[i] = cfg
+	}
+
+	// 1. wheel orders and start positions
+	var best []Result
+	var err error
+	for i, cfg := range configs {
+		searchOpts := opts.Options
+		if opts.Progress != nil {
+			offset := i * stateCount(cfg)
+			searchOpts.Progress = func(done, total int) {
+				opts.Progress(offset+done, len(configs)*total)
+			}
+		}
+
+		var results []Result
+		results, err = SearchPositions(ctx, cfg, searchOpts, func(machine *enigma.Enigma) (float64, bool) {
+			plaintext, _ := machine.Decrypt(text)
+			return scoring.IndexOfCoincidence(plaintext), true
+		})
+		best = append(best, results...)
+		sortResults(best)
+		if len(best) > candidates {
+			best = best[:candidates]
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return best, err
+	}
+
+	// 2. and 3. refine the candidates, one goroutine each up to the number of workers
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	jobs := make(chan int)
+	refined := make([]bool, len(best))
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex // guards climbErr
+		climbErr error
+	)
+	for w := 0; w < min(workers, len(best)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, err := refine(ctx, best[i], text, scorer, maxPlugs)
+				if err != nil {
+					errMu.Lock()
+					if climbErr == nil {
+						climbErr = err
+					}
+					errMu.Unlock()
+					continue
+				}
+				best[i] = result
+				refined[i] = true
+			}
+		}()
+	}
+
+feed:
+	for i := range best {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil {
+		err = climbErr
+	}
+	if err != nil {
+		// unrefined candidates are scored differently, leave them out
+		var done []Result
+		for i, result := range best {
+			if refined[i] {
+				done = append(done, result)
+			}
+		}
+		best = done
+	}
+	sortResults(best)
+	return best, err
+}
+
+// climbs the ring settings and then the plugboard of a candidate
+func refine(ctx context.Context, candidate Result, text string, scorer scoring.Scorer, maxPlugs int) (Result, error) {
+	result, err := climbRings(ctx, candidate, text, scorer)
+	if err != nil {
+		return Result{}, err
+	}
+	plugboard, score, err := ClimbPlugboard(ctx, result.Config, result.Start, text, PlugboardOptions{
+		MaxPairs: maxPlugs,
+		Scorer:   scorer,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	if result.Config, err = result.Config.WithPlugboard(plugboard); err != nil {
+		return Result{}, err
+	}
+	result.Score = score
+	return result, nil
+}
+
+// returns the number of start positions of a configuration
+func stateCount(cfg enigma.Config) int {
+	n := 1
+	for i := 0; i < cfg.RotorCount(); i++ {
+		n *= enigma.AlphabetSize
+	}
+	return n
+}
+
+// decrypts text with the configuration at the start position
+func decrypt(cfg enigma.Config, start enigma.State, text string) string {
+	machine, err := cfg.NewEnigma(start)
+	if err != nil {
+		return ""
+	}
+	plaintext, _ := machine.Decrypt(text)
+	return plaintext
+}
+
+// searches the ring setting of the right rotor, moving its position along so its wiring
+// core stays in place. A wrong turnover point of the right rotor also shifts the middle
+// and left rotor, so their positions are searched again for every ring. Last the ring of
+// the middle rotor is searched, it only decides when the left rotor turns over. The ring
+// of the left rotor only turns its core, which the start position already covers. If ctx
+// is done the search stops between sweeps with the context error.
+func climbRings(ctx context.Context, result Result, text string, scorer scoring.Scorer) (Result, error) {
+	best := result
+	best.Score = scorer.Score(decrypt(result.Config, result.Start, text))
+
+	// tries the rings and positions, keeping them if they score better
+	try := func(machine *enigma.Enigma, cfg enigma.Config, positions []int) {
+		start, err := enigma.NewState(positions...)
+		if err != nil || machine.SetState(start) != nil {
+			return
+		}
+		plaintext, _ := machine.Decrypt(text)
+		if score := scorer.Score(plaintext); score > best.Score {
+			best = Result{Config: cfg, Start: start, Score: score}
+		}
+	}
+
+	rings := result.Config.RingSettings()
+	positions := result.Start.Positions()
+	for right := 0; right < enigma.AlphabetSize; right++ {
+		// a sweep over the middle and left positions decrypts the text 676 times
+		if err := ctx.Err(); err != nil {
+			return best, err
+		}
+		newRings := append([]int(nil), rings...)
+		newRings[0] = right
+		cfg, err := result.Config.WithRingSettings(newRings...)
+		if err != nil {
+			continue
+		}
+		machine, err := cfg.NewEnigma(result.Start)
+		if err != nil {
+			continue
+		}
+
+		newPositions := append([]int(nil), positions...)
+		newPositions[0] = shift(positions[0], right-rings[0])
+		for middle := 0; middle < enigma.AlphabetSize; middle++ {
+			for left := 0; left < enigma.AlphabetSize; left++ {
+				newPositions[1], newPositions[2] = middle, left
+				try(machine, cfg, newPositions)
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return best, err
+	}
+	rings = best.Config.RingSettings()
+	positions = best.Start.Positions()
+	for middle := 0; middle < enigma.AlphabetSize; middle++ {
+		newRings := append([]int(nil), rings...)
+		newRings[1] = middle
+		cfg, err := best.Config.WithRingSettings(newRings...)
+		if err != nil {
+			continue
+		}
+		machine, err := cfg.NewEnigma(best.Start)
+		if err != nil {
+			continue
+		}
+
+		newPositions := append([]int(nil), positions...)
+		newPositions[1] = shift(positions[1], middle-rings[1])
+		try(machine, cfg, newPositions)
+	}
+	return best, nil
+}
+
+// returns the position moved by delta steps
+func shift(position, delta int) int {
+	return ((position+delta)%enigma.AlphabetSize + enigma.AlphabetSize) % enigma.AlphabetSize
+}
+
+// WheelOrders returns every way to place k distinct rotors chosen from the given types,
+// in machine order (rightmost first)
+func WheelOrders(rotors []string, k int) [][]string {
+	var orders [][]string
+	var current []string
+	used := make([]bool, len(rotors))
+
+	var walk func()
+	walk = func() {
+		if len(current) == k {
+			orders = append(orders, append([]string(nil), current...))
+			return
+		}
+		for i, rotor := range rotors {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			current = append(current, rotor)
+			walk()
+			current = current[:len(current)-1]
+			used[i] = false
+		}
+	}
+	walk()
+	return orders
+}
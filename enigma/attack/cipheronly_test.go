@@ -0,0 +1,97 @@
+package attack
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+const germanPlaintext = "ANDASOBERKOMMANDODERWEHRMACHTXDIEVERBAENDEDERDRITTENARMEEHABENDENFLUSSUEBERSCHRITTENUND" +
+	"STEHENJETZTVORDERSTADTXDERGEGNERLEISTETNURGERINGENWIDERSTANDXDIEEIGENENVERLUSTESINDGERING" +
+	"XDERNACHSCHUBANMUNITIONUNDBRENNSTOFFISTGESICHERTXWEITEREMELDUNGENFOLGENNACHEINTREFFENDER" +
+	"SPAEHTRUPPSXDASWETTERISTKLARUNDTROCKENXDIESTRASSENSINDGUTBEFAHRBARXENDEDERMELDUNG"
+
+func TestCiphertextOnly(t *testing.T) {
+	cfg, err := enigma.NewConfig([]string{"II", "IV", "I"}, "UKW-B", "AM FI NV", []int{7, 5, 0})
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	start, _ := enigma.StateFromString("HKB")
+	machine, _ := cfg.NewEnigma(start)
+	ciphertext, _ := machine.Encrypt(germanPlaintext)
+
+	results, err := CiphertextOnly(context.Background(), ciphertext, CiphertextOnlyOptions{
+		WheelOrders: [][]string{{"II", "IV", "I"}},
+		Candidates:  5,
+	})
+	if err != nil {
+		t.Fatalf("attack failed: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 candidates, got %d", len(results))
+	}
+
+	plaintext := decrypt(results[0].Config, results[0].Start, Letters(ciphertext))
+	if plaintext != germanPlaintext {
+		t.Errorf("best candidate %s %s decrypts to\n%s", results[0].Config, results[0].Start, plaintext)
+	}
+}
+
+func TestWheelOrders(t *testing.T) {
+	orders := WheelOrders([]string{"I", "II", "III", "IV", "V"}, 3)
+	if len(orders) != 60 {
+		t.Errorf("expected 60 wheel orders, got %d", len(orders))
+	}
+}
+
+func TestCiphertextOnlyCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CiphertextOnly(ctx, germanPlaintext, CiphertextOnlyOptions{WheelOrders: [][]string{{"I", "II", "III"}}})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCiphertextOnlyValidation(t *testing.T) {
+	_, err := CiphertextOnly(context.Background(), germanPlaintext, CiphertextOnlyOptions{
+		WheelOrders: [][]string{{"I", "II", "III"}},
+		MaxPlugs:    14,
+	})
+	if err == nil {
+		t.Errorf("expected error for 14 plugboard pairs")
+	}
+}
+
+func TestCiphertextOnlyCancelDuringRefinement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the scorer is first called when the candidates are refined
+	var calls atomic.Int64
+	scorer := scoring.ScorerFunc(func(text string) float64 {
+		calls.Add(1)
+		cancel()
+		return scoring.IndexOfCoincidence(text)
+	})
+
+	results, err := CiphertextOnly(ctx, germanPlaintext, CiphertextOnlyOptions{
+		WheelOrders: [][]string{{"I", "II", "III"}},
+		Candidates:  2,
+		Scorer:      scorer,
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no refined candidates, got %d", len(results))
+	}
+	// a full refinement scores the text tens of thousands of times
+	if n := calls.Load(); n > 100 {
+		t.Errorf("refinement went on after the cancel: %d scores", n)
+	}
+}
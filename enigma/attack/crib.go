@@ -0,0 +1,70 @@
+package attack
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/bombe"
+)
+
+// Placement is an offset at which a crib can stand in a ciphertext: no crib letter meets
+// the same ciphertext letter, which the Enigma would never produce.
+type Placement struct {
+	Crib   string
+	Offset int
+
+	// closed loops in the letter connections of crib and ciphertext. Loops are what lets
+	// the bombe reject wrong positions, a placement without loops is of little use.
+	Loops int
+
+	// Loops plus the crib length divided by 26, longer cribs break ties
+	Score float64
+}
+
+// DragCrib slides the crib along the ciphertext and returns every offset where no letter
+// coincides, best score first. Ciphertext and crib are reduced to their letters first.
+func DragCrib(ciphertext, crib string) ([]Placement, error) {
+	return DragCribs(ciphertext, crib)
+}
+
+// DragCribs drags several cribs along the same ciphertext and returns the placements of
+// all of them, best score first. A crib longer than the ciphertext has no placements.
+func DragCribs(ciphertext string, cribs ...string) ([]Placement, error) {
+	ciphertext = Letters(ciphertext)
+	if len(cribs) == 0 {
+		return nil, fmt.Errorf("no cribs")
+	}
+
+	var placements []Placement
+	for _, crib := range cribs {
+		crib = Letters(crib)
+		if crib == "" {
+			return nil, fmt.Errorf("empty crib")
+		}
+
+		for offset := 0; offset+len(crib) <= len(ciphertext); offset++ {
+			// the menu rejects placements where a letter meets itself
+			menu, err := bombe.NewMenu(crib, ciphertext, offset)
+			if err != nil {
+				continue
+			}
+			loops := menu.LoopCount()
+			placements = append(placements, Placement{
+				Crib:   crib,
+				Offset: offset,
+				Loops:  loops,
+				Score:  float64(loops) + float64(len(crib))/enigma.AlphabetSize,
+			})
+		}
+	}
+
+	slices.SortStableFunc(placements, func(a, b Placement) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+	return placements, nil
+}
@@ -0,0 +1,84 @@
+package attack
+
+import (
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestDragCrib(t *testing.T) {
+	placements, err := DragCrib("AXBCZ", "ABC")
+	if err != nil {
+		t.Fatalf("drag failed: %v", err)
+	}
+
+	// offset 0 puts A on A, offset 1 B on B and C on C, offset 2 is free
+	if len(placements) != 1 || placements[0].Offset != 2 {
+		t.Fatalf("expected only offset 2, got %+v", placements)
+	}
+	if placements[0].Crib != "ABC" {
+		t.Errorf("unexpected crib: %s", placements[0].Crib)
+	}
+}
+
+func TestDragCribFindsTruePlacement(t *testing.T) {
+	cfg, _ := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", "AB CD", nil)
+	start, _ := enigma.StateFromString("KDO")
+	machine, _ := cfg.NewEnigma(start)
+	ciphertext, _ := machine.Encrypt("ANXOKHQUEBXWETTERVORHERSAGEXBISKAYA")
+
+	placements, err := DragCribs(ciphertext, "wetter vorhersage", "biskaya")
+	if err != nil {
+		t.Fatalf("drag failed: %v", err)
+	}
+
+	found := map[string]bool{}
+	for _, p := range placements {
+		if (p.Crib == "WETTERVORHERSAGE" && p.Offset == 11) || (p.Crib == "BISKAYA" && p.Offset == 28) {
+			found[p.Crib] = true
+		}
+	}
+	if len(found) != 2 {
+		t.Errorf("true placements missing: %v", found)
+	}
+
+	for i := 1; i < len(placements); i++ {
+		if placements[i].Score > placements[i-1].Score {
+			t.Errorf("placements not sorted by score")
+		}
+	}
+}
+
+func TestDragCribCountsLoops(t *testing.T) {
+	// A-B, B-C, C-A closes one loop, D-E is a tree
+	placements, err := DragCrib("BCAE", "ABCD")
+	if err != nil {
+		t.Fatalf("drag failed: %v", err)
+	}
+	if len(placements) != 1 || placements[0].Loops != 1 {
+		t.Errorf("expected 1 loop, got %+v", placements)
+	}
+}
+
+func TestDragCribValidation(t *testing.T) {
+	if _, err := DragCribs("ABC"); err == nil {
+		t.Errorf("expected error without cribs")
+	}
+	if _, err := DragCrib("ABC", ""); err == nil {
+		t.Errorf("expected error for empty crib")
+	}
+
+	// a crib that does not fit is skipped, the others are still placed
+	placements, err := DragCribs("XYZ", "ABCD", "AB")
+	if err != nil {
+		t.Fatalf("drag failed: %v", err)
+	}
+	for _, p := range placements {
+		if p.Crib != "AB" {
+			t.Errorf("unexpected placement of %s", p.Crib)
+		}
+	}
+	if len(placements) != 2 {
+		t.Errorf("expected 2 placements of AB, got %d", len(placements))
+	}
+}
@@ -0,0 +1,199 @@
+package attack
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+// PlugboardOptions control ClimbPlugboard
+type PlugboardOptions struct {
+	// largest number of pairs, 0 means 10 (the wartime standard)
+	MaxPairs int
+
+	// number of additional climbs from random plugboards, 0 climbs once from an empty one
+	Restarts int
+
+	// seed for the random plugboards, 0 uses a random seed
+	Seed uint64
+
+	// rates decryptions, nil uses German bigrams
+	Scorer scoring.Scorer
+}
+
+// ClimbPlugboard recovers the plugboard when everything else about the key is known or
+// guessed. Starting from an empty plugboard it repeatedly makes the change that improves
+// the score of the decryption most, until no change helps. A change connects two free
+// letters, moves one end of a pair to a free letter, exchanges the partners of two pairs or
+// removes a pair. Restarts repeat the climb from random plugboards, which helps it out of
+// local maxima.
+//
+// The plugboard of cfg is ignored. The best plugboard is returned in the "AB CD" form
+// NewPlugboard accepts, together with its score. If ctx is cancelled the climb stops after
+// the current round and returns the best plugboard so far with the context error.
+func ClimbPlugboard(ctx context.Context, cfg enigma.Config, start enigma.State, ciphertext string, opts PlugboardOptions) (string, float64, error) {
+	text := Letters(ciphertext)
+	if text == "" {
+		return "", 0, fmt.Errorf("empty ciphertext")
+	}
+	if _, err := cfg.NewEnigma(start); err != nil {
+		return "", 0, err
+	}
+
+	maxPairs := opts.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = 10
+	}
+	if maxPairs > enigma.AlphabetSize/2 {
+		return "", 0, fmt.Errorf("at most %d plugboard pairs are possible", enigma.AlphabetSize/2)
+	}
+
+	scorer := opts.Scorer
+	if scorer == nil {
+		bigrams, err := scoring.German(2)
+		if err != nil {
+			return "", 0, err
+		}
+		scorer = bigrams
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		var buf [8]byte
+		if _, err := crand.Read(buf[:]); err != nil {
+			return "", 0, err
+		}
+		seed = binary.LittleEndian.Uint64(buf[:])
+	}
+	rng := rand.New(rand.NewPCG(seed, seed>>32))
+
+	c := &plugClimber{cfg: cfg, start: start, text: text, maxPairs: maxPairs, scorer: scorer}
+	best, bestScore, err := c.climb(ctx, identityPairs())
+	for i := 0; i < opts.Restarts && err == nil; i++ {
+		var partner [enigma.AlphabetSize]int
+		var score float64
+		partner, score, err = c.climb(ctx, randomPairs(rng, rng.IntN(maxPairs+1)))
+		if score > bestScore {
+			best, bestScore = partner, score
+		}
+	}
+	return plugboardString(best), bestScore, err
+}
+
+// plugClimber holds what stays fixed during a climb
+type plugClimber struct {
+	cfg      enigma.Config
+	start    enigma.State
+	text     string
+	maxPairs int
+	scorer   scoring.Scorer
+}
+
+// rates the decryption with a plugboard given as the partner of every letter
+func (c *plugClimber) score(partner [enigma.AlphabetSize]int) float64 {
+	cfg, err := c.cfg.WithPlugboard(plugboardString(partner))
+	if err != nil {
+		return 0
+	}
+	return c.scorer.Score(decrypt(cfg, c.start, c.text))
+}
+
+// climbs from the given plugboard until no change improves the score or ctx is done
+func (c *plugClimber) climb(ctx context.Context, partner [enigma.AlphabetSize]int) ([enigma.AlphabetSize]int, float64, error) {
+	bestScore := c.score(partner)
+	for {
+		if err := ctx.Err(); err != nil {
+			return partner, bestScore, err
+		}
+		improved := false
+		bestPartner := partner
+		pairs := pairCount(partner)
+
+		for a := 0; a < enigma.AlphabetSize; a++ {
+			for b := a + 1; b < enigma.AlphabetSize; b++ {
+				next := partner
+				x, y := partner[a], partner[b]
+				switch {
+				case x == b:
+					// remove the pair
+					next[a], next[b] = a, b
+				case x == a && y == b:
+					// connect two free letters
+					if pairs >= c.maxPairs {
+						continue
+					}
+					next[a], next[b] = b, a
+				case y == b:
+					// move the other end of a's pair to b
+					next[x] = x
+					next[a], next[b] = b, a
+				case x == a:
+					next[y] = y
+					next[a], next[b] = b, a
+				default:
+					// exchange partners: a-x b-y becomes a-b x-y
+					next[a], next[b] = b, a
+					next[x], next[y] = y, x
+				}
+
+				if s := c.score(next); s > bestScore {
+					bestScore, bestPartner = s, next
+					improved = true
+				}
+			}
+		}
+
+		if !improved {
+			return partner, bestScore, nil
+		}
+		partner = bestPartner
+	}
+}
+
+// returns a plugboard without pairs
+func identityPairs() [enigma.AlphabetSize]int {
+	var partner [enigma.AlphabetSize]int
+	for i := range partner {
+		partner[i] = i
+	}
+	return partner
+}
+
+// returns a plugboard with n random pairs
+func randomPairs(rng *rand.Rand, n int) [enigma.AlphabetSize]int {
+	partner := identityPairs()
+	letters := rng.Perm(enigma.AlphabetSize)
+	for i := 0; i < n; i++ {
+		a, b := letters[2*i], letters[2*i+1]
+		partner[a], partner[b] = b, a
+	}
+	return partner
+}
+
+// returns the number of connected pairs
+func pairCount(partner [enigma.AlphabetSize]int) int {
+	n := 0
+	for i, p := range partner {
+		if p > i {
+			n++
+		}
+	}
+	return n
+}
+
+// formats the pairs in the "AB CD" form accepted by NewPlugboard
+func plugboardString(partner [enigma.AlphabetSize]int) string {
+	var pairs []string
+	for i, p := range partner {
+		if p > i {
+			pairs = append(pairs, string([]byte{byte('A' + i), byte('A' + p)}))
+		}
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,69 @@
+package attack
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+func TestClimbPlugboard(t *testing.T) {
+	const plugboard = "AM BT CQ FI GX HK LR NV PS UZ"
+	cfg, _ := enigma.NewConfig([]string{"V", "I", "III"}, "UKW-B", plugboard, []int{4, 11, 19})
+	start, _ := enigma.StateFromString("QBN")
+	machine, _ := cfg.NewEnigma(start)
+	ciphertext, _ := machine.Encrypt(germanPlaintext)
+
+	trigrams, _ := scoring.German(3)
+	found, score, err := ClimbPlugboard(context.Background(), cfg, start, ciphertext, PlugboardOptions{
+		Restarts: 2,
+		Seed:     1,
+		Scorer:   trigrams,
+	})
+	if err != nil {
+		t.Fatalf("climb failed: %v", err)
+	}
+	if found != plugboard {
+		t.Errorf("expected plugboard %s, got %s", plugboard, found)
+	}
+
+	solved, _ := cfg.WithPlugboard(found)
+	if want := trigrams.Score(decrypt(solved, start, ciphertext)); score != want {
+		t.Errorf("score %v does not match the decryption's score %v", score, want)
+	}
+}
+
+func TestClimbPlugboardValidation(t *testing.T) {
+	cfg, _ := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", "", nil)
+	start, _ := enigma.StateFromString("AAA")
+
+	if _, _, err := ClimbPlugboard(context.Background(), cfg, start, "", PlugboardOptions{}); err == nil {
+		t.Errorf("expected error for empty ciphertext")
+	}
+	if _, _, err := ClimbPlugboard(context.Background(), cfg, start, "ABC", PlugboardOptions{MaxPairs: 14}); err == nil {
+		t.Errorf("expected error for 14 pairs")
+	}
+	wrong, _ := enigma.StateFromString("AA")
+	if _, _, err := ClimbPlugboard(context.Background(), cfg, wrong, "ABC", PlugboardOptions{}); err == nil {
+		t.Errorf("expected error for a state that does not fit the configuration")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := ClimbPlugboard(ctx, cfg, start, "ABC", PlugboardOptions{Restarts: 5}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPlugboardString(t *testing.T) {
+	partner := identityPairs()
+	partner[0], partner[25] = 25, 0
+	partner[3], partner[1] = 1, 3
+	if got := plugboardString(partner); got != "AZ BD" {
+		t.Errorf("expected AZ BD, got %s", got)
+	}
+	if pairCount(partner) != 2 {
+		t.Errorf("expected 2 pairs, got %d", pairCount(partner))
+	}
+}
@@ -0,0 +1,269 @@
+package attack
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+// RingOptions control RecoverRings
+type RingOptions struct {
+	// rates decryptions, nil uses German bigrams
+	Scorer scoring.Scorer
+
+	// number of letters averaged to decide whether a stretch of text is readable,
+	// 0 means 20. Garbled stretches much shorter than the window go unnoticed.
+	Window int
+}
+
+// RingRecovery is the outcome of RecoverRings
+type RingRecovery struct {
+	Config enigma.Config // the configuration with the recovered ring settings
+	Start  enigma.State  // start position, the rotor cores are where they were
+
+	// letter indexes where the original decryption turned from readable to garbled or
+	// back, as far as the window allows to tell
+	Breaks []int
+
+	// score of the new decryption by the scorer
+	Score float64
+}
+
+// RecoverRings corrects the ring settings of the fast and middle rotor of a key that
+// decrypts a message only in parts.
+//
+// A ring setting turns the wiring and the notch of a rotor together. Changing the ring and
+// the position of a rotor by the same amount leaves its wiring core in place and only
+// moves its turnover. A key with the right cores but wrong rings therefore decrypts
+// readable text until a turnover happens at a different letter than in the real machine.
+// RecoverRings finds these break points, works out which fast and middle ring settings
+// move the turnovers onto them and keeps the ones with the best decryption. Candidates
+// are only simulated, a decryption is scored for each plausible ring, which makes this
+// far cheaper than trying all 676 combinations.
+func RecoverRings(cfg enigma.Config, start enigma.State, ciphertext string, opts RingOptions) (RingRecovery, error) {
+	text := Letters(ciphertext)
+	if text == "" {
+		return RingRecovery{}, fmt.Errorf("empty ciphertext")
+	}
+	if _, err := cfg.NewEnigma(start); err != nil {
+		return RingRecovery{}, err
+	}
+
+	scorer := opts.Scorer
+	if scorer == nil {
+		bigrams, err := scoring.German(2)
+		if err != nil {
+			return RingRecovery{}, err
+		}
+		scorer = bigrams
+	}
+	window := opts.Window
+	if window <= 0 {
+		window = 20
+	}
+
+	p := newProfiler(scorer, window)
+	result := RingRecovery{
+		Config: cfg,
+		Start:  start,
+		Breaks: p.breaks(decrypt(cfg, start, text)),
+		Score:  scorer.Score(decrypt(cfg, start, text)),
+	}
+
+	// the fast rotor moves the middle one, the middle rotor the left one. A wrong middle
+	// ring can garble everything after an early turnover and hide the breaks of the fast
+	// rotor, so both are corrected until neither improves. Every change raises the score,
+	// which ends the loop.
+	for changed := true; changed; {
+		changed = false
+		for _, rotor := range []int{0, 1} {
+			better, ok, err := improveRing(result, rotor, text, p)
+			if err != nil {
+				return RingRecovery{}, err
+			}
+			if ok {
+				result, changed = better, true
+			}
+		}
+	}
+	return result, nil
+}
+
+// tries the ring settings of a rotor whose turnovers fit the break points of the current
+// decryption and returns the best one if it improves the score
+func improveRing(current RingRecovery, rotor int, text string, p *profiler) (RingRecovery, bool, error) {
+	breaks := p.breaks(decrypt(current.Config, current.Start, text))
+	if len(breaks) == 0 {
+		return current, false, nil
+	}
+	steps := stepLetters(current.Config, current.Start, len(text), rotor+1)
+
+	best, improved := current, false
+	for delta := 1; delta < enigma.AlphabetSize; delta++ {
+		cfg, start, err := moveRing(current.Config, current.Start, rotor, delta)
+		if err != nil {
+			return current, false, err
+		}
+
+		// the decryption breaks wherever the current key and the real machine turn the
+		// next rotor at different letters
+		moved := stepLetters(cfg, start, len(text), rotor+1)
+		if !explainsBreaks(moved, steps, breaks, p.window) {
+			continue
+		}
+
+		if score := p.scorer.Score(decrypt(cfg, start, text)); score > best.Score {
+			best.Config, best.Start, best.Score = cfg, start, score
+			improved = true
+		}
+	}
+	return best, improved, nil
+}
+
+// changes the ring setting and the position of a rotor by delta, leaving its core in place
+func moveRing(cfg enigma.Config, start enigma.State, rotor, delta int) (enigma.Config, enigma.State, error) {
+	rings := cfg.RingSettings()
+	positions := start.Positions()
+	rings[rotor] = shift(rings[rotor], delta)
+	positions[rotor] = shift(positions[rotor], delta)
+
+	newCfg, err := cfg.WithRingSettings(rings...)
+	if err != nil {
+		return enigma.Config{}, enigma.State{}, err
+	}
+	newStart, err := enigma.NewState(positions...)
+	return newCfg, newStart, err
+}
+
+// returns the indexes of the letters at which the rotor moves
+func stepLetters(cfg enigma.Config, start enigma.State, length int, rotor int) []int {
+	machine, err := cfg.NewEnigma(start)
+	if err != nil {
+		return nil
+	}
+
+	var letters []int
+	before := machine.GetRotorPositions()[rotor]
+	for i := 0; i < length; i++ {
+		machine.EncryptChar('A')
+		after := machine.GetRotorPositions()[rotor]
+		if after != before {
+			letters = append(letters, i)
+		}
+		before = after
+	}
+	return letters
+}
+
+// reports whether most of the turnovers in which the candidate differs from the current
+// key lie near break points. Short garbled stretches can escape the profile, so a few
+// unexplained differences are allowed.
+func explainsBreaks(candidate, current, breaks []int, tolerance int) bool {
+	nearBreak := func(letter int) bool {
+		return slices.ContainsFunc(breaks, func(b int) bool {
+			return abs(letter-b) <= tolerance
+		})
+	}
+
+	differences, explained := 0, 0
+	for _, pair := range [][2][]int{{candidate, current}, {current, candidate}} {
+		for _, letter := range pair[0] {
+			if slices.Contains(pair[1], letter) {
+				continue
+			}
+			differences++
+			if nearBreak(letter) {
+				explained++
+			}
+		}
+	}
+	return differences > 0 && 2*explained >= differences
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+//-------------------- profiler -----------------------------
+
+// profiler tells readable stretches of a decryption from garbled ones
+type profiler struct {
+	scorer scoring.Scorer
+	window int
+	random float64 // average score of a window of random letters
+}
+
+func newProfiler(scorer scoring.Scorer, window int) *profiler {
+	// a fixed seed keeps the reference, and so the break points, reproducible
+	const samples = 100
+	rng := rand.New(rand.NewPCG(1, 2))
+	text := make([]byte, window)
+	random := 0.0
+	for range samples {
+		for i := range text {
+			text[i] = byte('A' + rng.IntN(enigma.AlphabetSize))
+		}
+		random += scorer.Score(string(text))
+	}
+	return &profiler{scorer: scorer, window: window, random: random / samples}
+}
+
+// returns the letter indexes where the text changes between readable and garbled. With
+// the right cores some stretch of the decryption is readable, its windows tell how the
+// language scores.
+func (p *profiler) breaks(text string) []int {
+	windows := len(text) - p.window + 1
+	if windows < 1 {
+		return nil
+	}
+
+	scores := make([]float64, windows)
+	best := math.Inf(-1)
+	for i := range scores {
+		scores[i] = p.scorer.Score(text[i : i+p.window])
+		best = max(best, scores[i])
+	}
+
+	// the windows closer to the best than to random letters estimate the score of the
+	// language, the threshold lies halfway between that and random letters
+	language, count := 0.0, 0
+	for _, score := range scores {
+		if score > (best+p.random)/2 {
+			language += score
+			count++
+		}
+	}
+	if count == 0 {
+		// nothing scores better than random letters
+		return nil
+	}
+	threshold := (language/float64(count) + p.random) / 2
+
+	// each window decides the letter at its centre
+	readable := make([]bool, len(text))
+	for i, score := range scores {
+		readable[i+p.window/2] = score > threshold
+	}
+	// the edges take the value of the nearest full window
+	for i := 0; i < p.window/2; i++ {
+		readable[i] = readable[p.window/2]
+	}
+	for i := windows + p.window/2; i < len(text); i++ {
+		readable[i] = readable[windows-1+p.window/2]
+	}
+
+	var breaks []int
+	for i := 1; i < len(text); i++ {
+		if readable[i] != readable[i-1] {
+			breaks = append(breaks, i)
+		}
+	}
+	return breaks
+}
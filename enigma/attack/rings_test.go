@@ -0,0 +1,76 @@
+package attack
+
+import (
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+func TestRecoverRings(t *testing.T) {
+	tests := []struct {
+		name      string
+		rings     []int
+		positions string
+	}{
+		// the fast rotor turns the middle one 11 letters later than with ring A
+		{"fast", []int{11, 0, 0}, "LKB"},
+		// the middle rotor reaches its notch (E) within the message, with ring A it would not
+		{"middle", []int{0, 9, 0}, "CMF"},
+		{"both", []int{17, 9, 0}, "RMF"},
+	}
+	// any scorer works, not only the n-gram models
+	trigrams, err := scoring.German(3)
+	if err != nil {
+		t.Fatalf("failed to load trigrams: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", "AQ EZ", tt.rings)
+			start, _ := enigma.StateFromString(tt.positions)
+			machine, _ := cfg.NewEnigma(start)
+			ciphertext, _ := machine.Encrypt(germanPlaintext)
+
+			// the same rotor cores with all rings at A
+			guessCfg, _ := cfg.WithRingSettings(0, 0, 0)
+			positions := start.Positions()
+			for i, ring := range tt.rings {
+				positions[i] = shift(positions[i], -ring)
+			}
+			guessStart, _ := enigma.NewState(positions...)
+			if decrypt(guessCfg, guessStart, ciphertext) == germanPlaintext {
+				t.Fatalf("the guessed key already decrypts the message")
+			}
+
+			result, err := RecoverRings(guessCfg, guessStart, ciphertext, RingOptions{
+				Scorer: scoring.ScorerFunc(trigrams.Score),
+			})
+			if err != nil {
+				t.Fatalf("recovery failed: %v", err)
+			}
+			if len(result.Breaks) == 0 {
+				t.Errorf("no break points found")
+			}
+			if plaintext := decrypt(result.Config, result.Start, ciphertext); plaintext != germanPlaintext {
+				t.Errorf("recovered %s %s (breaks %v) decrypts to\n%s",
+					result.Config, result.Start, result.Breaks, plaintext)
+			}
+		})
+	}
+}
+
+func TestRecoverRingsCorrectKey(t *testing.T) {
+	cfg, _ := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", "", []int{3, 4, 0})
+	start, _ := enigma.StateFromString("ABC")
+	machine, _ := cfg.NewEnigma(start)
+	ciphertext, _ := machine.Encrypt(germanPlaintext)
+
+	result, err := RecoverRings(cfg, start, ciphertext, RingOptions{})
+	if err != nil {
+		t.Fatalf("recovery failed: %v", err)
+	}
+	if result.Config != cfg || result.Start != start || len(result.Breaks) != 0 {
+		t.Errorf("a correct key should be kept, got %s %s breaks %v", result.Config, result.Start, result.Breaks)
+	}
+}
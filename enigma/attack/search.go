@@ -0,0 +1,197 @@
+package attack
+
+/*
+	Attack contains cryptanalytic tools for the Enigma machine.
+
+	The position search in this file is the foundation the other attacks build on: it runs
+	an evaluation function for every start position of a configuration, spread over several
+	goroutines, and collects the positions the evaluation accepts.
+*/
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Options control how a search runs
+type Options struct {
+	// number of goroutines, 0 uses runtime.GOMAXPROCS(0)
+	Workers int
+
+	// called after each finished unit of work with the number of start positions
+	// searched so far and in total, never from two goroutines at the same time
+	Progress func(done, total int)
+}
+
+// Result is a start position accepted by a search
+type Result struct {
+	Config enigma.Config
+	Start  enigma.State
+	Score  float64
+}
+
+// Evaluator scores the machine set to a start position. It may encrypt with the machine,
+// which is reset before the next call. Positions for which keep is false are dropped.
+type Evaluator func(machine *enigma.Enigma) (score float64, keep bool)
+
+// SearchPositions evaluates every start position of cfg and returns the kept ones, best
+// score first. The work is split by the positions of the two slowest rotors and spread
+// over Options.Workers goroutines. If ctx is cancelled the search stops early and returns
+// the results found so far together with the context error.
+func SearchPositions(ctx context.Context, cfg enigma.Config, opts Options, evaluate Evaluator) ([]Result, error) {
+	rotors := cfg.RotorCount()
+	if rotors < 3 {
+		return nil, fmt.Errorf("invalid configuration: %d rotors", rotors)
+	}
+
+	// build the machine before starting the workers, a worker that could not build one
+	// would leave the feed loop waiting for it
+	template, err := cfg.NewEnigma(stateAt(rotors, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// a unit fixes the two leftmost rotors and runs through all the others
+	units := enigma.AlphabetSize * enigma.AlphabetSize
+	perUnit := 1
+	for i := 0; i < rotors-2; i++ {
+		perUnit *= enigma.AlphabetSize
+	}
+	total := units * perUnit
+
+	jobs := make(chan int)
+	var (
+		mu      sync.Mutex // guards results, done and calls to Progress
+		results []Result
+		done    int
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			machine := template.Clone()
+			for unit := range jobs {
+				var found []Result
+				for i := 0; i < perUnit; i++ {
+					start := stateAt(rotors, unit*perUnit+i)
+					machine.SetState(start)
+					if score, keep := evaluate(machine); keep {
+						found = append(found, Result{Config: cfg, Start: start, Score: score})
+					}
+				}
+
+				mu.Lock()
+				results = append(results, found...)
+				done += perUnit
+				if opts.Progress != nil {
+					opts.Progress(done, total)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for unit := 0; unit < units; unit++ {
+		select {
+		case jobs <- unit:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sortResults(results)
+	return results, err
+}
+
+// returns the n-th start position of a machine with the given number of rotors.
+// Positions are numbered with the leftmost rotor as the most significant digit.
+func stateAt(rotors int, n int) enigma.State {
+	positions := make([]int, rotors)
+	for i := 0; i < rotors; i++ {
+		positions[i] = n % enigma.AlphabetSize
+		n /= enigma.AlphabetSize
+	}
+	state, _ := enigma.NewState(positions...)
+	return state
+}
+
+// sorts results by descending score, ties in order of configuration and start position
+func sortResults(results []Result) {
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Config.String(), b.Config.String()); c != 0 {
+			return c
+		}
+		return slices.Compare(reversed(a.Start.Positions()), reversed(b.Start.Positions()))
+	})
+}
+
+// returns positions from the leftmost rotor to the rightmost
+func reversed(positions []int) []int {
+	slices.Reverse(positions)
+	return positions
+}
+
+// KnownPlaintext finds the start positions of cfg at which the crib, placed at offset in
+// the ciphertext, enciphers to the ciphertext letters at that place. Crib and ciphertext
+// are reduced to their letters first. The score of a result is the length of the crib.
+func KnownPlaintext(ctx context.Context, cfg enigma.Config, crib, ciphertext string, offset int, opts Options) ([]Result, error) {
+	crib, ciphertext = Letters(crib), Letters(ciphertext)
+	if crib == "" {
+		return nil, fmt.Errorf("empty crib")
+	}
+	if offset < 0 || offset+len(crib) > len(ciphertext) {
+		return nil, fmt.Errorf("crib of length %d at offset %d does not fit a ciphertext of length %d",
+			len(crib), offset, len(ciphertext))
+	}
+
+	return SearchPositions(ctx, cfg, opts, func(machine *enigma.Enigma) (float64, bool) {
+		// the letters before the crib only move the rotors
+		for i := 0; i < offset; i++ {
+			machine.EncryptChar('A')
+		}
+		for i := 0; i < len(crib); i++ {
+			out, _ := machine.EncryptChar(rune(crib[i]))
+			if byte(out) != ciphertext[offset+i] {
+				return 0, false
+			}
+		}
+		return float64(len(crib)), true
+	})
+}
+
+// Letters returns the letters of s in upper case, everything else is dropped
+func Letters(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, char := range s {
+		if char >= 'a' && char <= 'z' {
+			char = char - 'a' + 'A'
+		}
+		if char >= 'A' && char <= 'Z' {
+			sb.WriteRune(char)
+		}
+	}
+	return sb.String()
+}
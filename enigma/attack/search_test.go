@@ -0,0 +1,78 @@
+package attack
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestKnownPlaintextFindsStart(t *testing.T) {
+	cfg, err := enigma.NewConfig([]string{"III", "II", "I"}, "UKW-B", "AB CD EF", []int{3, 7, 11})
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	start, _ := enigma.StateFromString("QEV")
+	machine, _ := cfg.NewEnigma(start)
+
+	plaintext := "KEINEBESONDERENEREIGNISSEWETTERGUT"
+	ciphertext, _ := machine.Encrypt(plaintext)
+
+	var progressCalls, lastDone, lastTotal int
+	results, err := KnownPlaintext(context.Background(), cfg, "WETTERGUT", ciphertext, 25, Options{
+		Workers: 4,
+		Progress: func(done, total int) {
+			progressCalls++
+			lastDone, lastTotal = done, total
+		},
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	found := false
+	for _, result := range results {
+		if result.Start == start {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("start %s not among %d results", start, len(results))
+	}
+
+	if progressCalls != 676 || lastDone != 17576 || lastTotal != 17576 {
+		t.Errorf("unexpected progress: %d calls, %d/%d", progressCalls, lastDone, lastTotal)
+	}
+}
+
+func TestSearchPositionsCancel(t *testing.T) {
+	cfg, _ := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", "", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var evaluated atomic.Int64
+	_, err := SearchPositions(ctx, cfg, Options{Workers: 2}, func(machine *enigma.Enigma) (float64, bool) {
+		if evaluated.Add(1) == 100 {
+			cancel()
+		}
+		return 0, false
+	})
+
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if evaluated.Load() >= 17576 {
+		t.Errorf("search did not stop early")
+	}
+}
+
+func TestKnownPlaintextValidation(t *testing.T) {
+	cfg, _ := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", "", nil)
+
+	if _, err := KnownPlaintext(context.Background(), cfg, "", "ABC", 0, Options{}); err == nil {
+		t.Errorf("expected error for empty crib")
+	}
+	if _, err := KnownPlaintext(context.Background(), cfg, "ABCD", "ABC", 0, Options{}); err == nil {
+		t.Errorf("expected error for crib longer than the ciphertext")
+	}
+}
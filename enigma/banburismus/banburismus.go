@@ -0,0 +1,266 @@
+package banburismus
+
+/*
+	Banburismus is Turing's statistical attack on the naval Enigma. All messages of a day
+	share the wheel order, ring settings and plugboard but start at different message
+	settings, which are sent enciphered as indicators. Two messages whose indicators agree
+	in every letter but the one of the fast rotor started with the same middle and left
+	rotor, so one of them is a stretch of the other's key stream, shifted by the distance
+	between their fast rotor positions. Written one above the other at that offset they
+	are in depth and show repeated letters about twice as often as at any other offset.
+
+	Bletchley Park punched the messages into long sheets printed in Banbury, slid them over
+	each other and counted the repeats, weighing the evidence in decibans. The offsets found
+	tell how far apart the indicator letters of the fast rotor are, and chained together in
+	the "scritchmus" they give the letters' true positions and where the turnover notch of
+	the fast rotor is, which leaves only a few rotors for the right wheel.
+
+	Indicators are read in machine order like enigma.State: the first letter belongs to the
+	fast rotor. The naval M3 and the four rotor M4 are supported, for the M4 the indicator
+	has a fourth letter for the Greek wheel.
+*/
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/attack"
+)
+
+// NavalRotors are the eight rotors of the Kriegsmarine
+var NavalRotors = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII"}
+
+// GermanRepeatRate is the probability that two letters of German naval plaintext are the same
+const GermanRepeatRate = 0.076
+
+// Message is an intercepted message with its indicator
+type Message struct {
+	// the message setting enciphered at the day's ground setting, in machine order
+	// (rightmost first), 3 letters for the M3 and 4 for the M4
+	Indicator string
+
+	Text string
+}
+
+// Options control Run
+type Options struct {
+	// rotors the right wheel is chosen from, nil uses NavalRotors
+	Rotors []string
+
+	// Greek wheels of the M4, nil for the M3
+	GreekWheels []string
+
+	// probability that two plaintext letters repeat, 0 uses GermanRepeatRate
+	RepeatRate float64
+
+	// fewest letters two messages must overlap at an offset to be compared, 0 means 50
+	MinOverlap int
+
+	// evidence in decibans needed to accept an offset, 0 means 10 (odds of 10 to 1)
+	Threshold float64
+}
+
+// Alignment is the best offset found between two messages with the same middle and left
+// indicator letters
+type Alignment struct {
+	A, B int // indexes of the messages, A started first
+
+	// positions of the fast rotor B started after A: letter i+Offset of A stands above
+	// letter i of B
+	Offset int
+
+	Overlap, Repeats int
+	Decibans         float64
+
+	// decibans of the opposite direction, B started 26-Offset positions before A. Which
+	// of the two is in depth tells whether the fast rotor turned over between the starts.
+	Reverse float64
+}
+
+func (a Alignment) String() string {
+	return fmt.Sprintf("%d+%d %d: %d/%d repeats, %.1f dB", a.A, a.Offset, a.B, a.Repeats, a.Overlap, a.Decibans)
+}
+
+// Result is the outcome of Run
+type Result struct {
+	// accepted offsets, strongest first
+	Alignments []Alignment
+
+	// offsets above the threshold that contradict stronger ones, most likely chance
+	Conflicts []Alignment
+
+	// indicator letters of the fast rotor with known distances, longest first
+	Chains []Chain
+
+	// rotors that can be the right wheel in every chain
+	RightWheels []string
+
+	// the wheel orders left, in machine order (rightmost first)
+	WheelOrders [][]string
+}
+
+// Decibans returns the evidence that two texts overlapping in overlap letters with the
+// given number of repeats are in depth rather than unrelated. A repeat is rate*26 times
+// more likely in depth, a non-repeat slightly less likely.
+func Decibans(overlap, repeats int, rate float64) float64 {
+	hit := 10 * math.Log10(rate*enigma.AlphabetSize)
+	miss := 10 * math.Log10((1-rate)*enigma.AlphabetSize/(enigma.AlphabetSize-1))
+	return float64(repeats)*hit + float64(overlap-repeats)*miss
+}
+
+// Run compares all messages whose indicators differ only in the letter of the fast rotor
+// at every offset, chains the accepted offsets and narrows the wheel orders down to
+// those whose right wheel fits.
+//
+// Like the historical method it assumes the middle rotor does not double step within the
+// messages, a turnover of the fast rotor between the starts of two messages keeps them out
+// of depth.
+func Run(messages []Message, opts Options) (Result, error) {
+	rotors := opts.Rotors
+	if rotors == nil {
+		rotors = NavalRotors
+	}
+	for _, rotor := range append(slices.Clone(rotors), opts.GreekWheels...) {
+		if _, err := enigma.NewHistoricalRotor(rotor); err != nil {
+			return Result{}, err
+		}
+	}
+	rate := opts.RepeatRate
+	if rate <= 0 {
+		rate = GermanRepeatRate
+	}
+	minOverlap := opts.MinOverlap
+	if minOverlap <= 0 {
+		minOverlap = 50
+	}
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = 10
+	}
+
+	indicatorLength := 3
+	if opts.GreekWheels != nil {
+		indicatorLength = 4
+	}
+	indicators := make([]string, len(messages))
+	texts := make([]string, len(messages))
+	for i, message := range messages {
+		indicators[i] = attack.Letters(message.Indicator)
+		if len(indicators[i]) != indicatorLength {
+			return Result{}, fmt.Errorf("message %d: indicator %q must have %d letters", i, message.Indicator, indicatorLength)
+		}
+		texts[i] = attack.Letters(message.Text)
+	}
+
+	// only messages that started with the same middle and left rotor can be in depth
+	groups := make(map[string][]int)
+	for i, indicator := range indicators {
+		groups[indicator[1:]] = append(groups[indicator[1:]], i)
+	}
+
+	var candidates []Alignment
+	for _, group := range groups {
+		for x, a := range group {
+			for _, b := range group[x+1:] {
+				if indicators[a] == indicators[b] {
+					continue // same setting, in depth without any offset
+				}
+				best, ok := align(texts, a, b, rate, minOverlap)
+				if ok && best.Decibans >= threshold {
+					candidates = append(candidates, best)
+				}
+			}
+		}
+	}
+	slices.SortFunc(candidates, func(x, y Alignment) int {
+		if c := cmp.Compare(y.Decibans, x.Decibans); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(x.A, y.A); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.B, y.B)
+	})
+
+	var result Result
+	chains := newLetterChains()
+	for _, alignment := range candidates {
+		if chains.add(indicators[alignment.A][0], indicators[alignment.B][0], alignment.Offset) {
+			result.Alignments = append(result.Alignments, alignment)
+		} else {
+			result.Conflicts = append(result.Conflicts, alignment)
+		}
+	}
+
+	result.Chains = chains.build(result.Alignments, indicators, rotors, threshold)
+	result.RightWheels = rightWheels(result.Chains, rotors)
+	for _, order := range attack.WheelOrders(rotors, 3) {
+		if !slices.Contains(result.RightWheels, order[0]) {
+			continue
+		}
+		if opts.GreekWheels == nil {
+			result.WheelOrders = append(result.WheelOrders, order)
+			continue
+		}
+		for _, greek := range opts.GreekWheels {
+			result.WheelOrders = append(result.WheelOrders, append(slices.Clone(order), greek))
+		}
+	}
+	return result, nil
+}
+
+// finds the offset with the most evidence that message b is a shifted stretch of message
+// a's key stream or the other way round
+func align(texts []string, a, b int, rate float64, minOverlap int) (Alignment, bool) {
+	var best Alignment
+	found := false
+	for _, pair := range [][2]int{{a, b}, {b, a}} {
+		first, second := texts[pair[0]], texts[pair[1]]
+		for offset := 1; offset < enigma.AlphabetSize; offset++ {
+			overlap, repeats := compare(first, second, offset)
+			if overlap < minOverlap {
+				continue
+			}
+			score := Decibans(overlap, repeats, rate)
+			if !found || score > best.Decibans {
+				best = Alignment{
+					A: pair[0], B: pair[1], Offset: offset,
+					Overlap: overlap, Repeats: repeats, Decibans: score,
+				}
+				found = true
+			}
+		}
+	}
+	if found {
+		overlap, repeats := compare(texts[best.B], texts[best.A], enigma.AlphabetSize-best.Offset)
+		best.Reverse = Decibans(overlap, repeats, rate)
+	}
+	return best, found
+}
+
+// counts the letters of b that equal the letter offset places further on in a
+func compare(a, b string, offset int) (overlap, repeats int) {
+	overlap = min(len(a)-offset, len(b))
+	for i := 0; i < overlap; i++ {
+		if a[i+offset] == b[i] {
+			repeats++
+		}
+	}
+	return max(overlap, 0), repeats
+}
+
+// formats letters with their distances as a strip, dots for unknown letters
+func strip(letters []byte, distances []int) string {
+	if len(letters) == 0 {
+		return ""
+	}
+	row := []byte(strings.Repeat(".", distances[len(distances)-1]+1))
+	for i, letter := range letters {
+		row[distances[i]] = letter
+	}
+	return string(row)
+}
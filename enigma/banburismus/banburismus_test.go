@@ -0,0 +1,122 @@
+package banburismus
+
+import (
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/attack"
+)
+
+// enciphers stretches of German text at message settings that share a few middle and left
+// rotor positions, as many messages of a day did
+func dayTraffic(t *testing.T, cfg enigma.Config, ground enigma.State, greek bool) ([]Message, map[byte]byte) {
+	t.Helper()
+	sample, err := os.ReadFile("testdata/german.txt")
+	if err != nil {
+		t.Fatalf("failed to read sample text: %v", err)
+	}
+	text := attack.Letters(string(sample))
+
+	rng := rand.New(rand.NewPCG(7, 7))
+	settings := make(map[byte]byte) // fast rotor indicator letter to true position
+	var messages []Message
+	used := 0
+	// the middle rotor IV has its notch at J, starting from K to N it stays clear of it
+	for _, middle := range []int{10, 12, 13} {
+		left := rng.IntN(enigma.AlphabetSize)
+		for _, right := range rng.Perm(enigma.AlphabetSize)[:12] {
+			positions := []int{right, middle, left}
+			if greek {
+				positions = append(positions, 0)
+			}
+			setting, _ := enigma.NewState(positions...)
+
+			machine, _ := cfg.NewEnigma(ground)
+			indicator, _ := machine.Encrypt(setting.String())
+			settings[indicator[0]] = byte('A' + right)
+
+			// the sample is reused from the start when it runs out, at other positions
+			// of the machine that does not put messages in depth
+			length := 250 + rng.IntN(100)
+			if used+length > len(text) {
+				used = 0
+			}
+			machine.SetState(setting)
+			ciphertext, _ := machine.Encrypt(text[used : used+length])
+			used += length
+			messages = append(messages, Message{Indicator: indicator, Text: ciphertext})
+		}
+	}
+	return messages, settings
+}
+
+func TestRunFindsRightWheel(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		rotors    []string
+		reflector string
+		greek     []string
+	}{
+		{"M3", []string{"II", "IV", "VII"}, "UKW-B", nil},
+		{"M4", []string{"III", "IV", "VI", "Beta"}, "UKW-B-thin", []string{"Beta", "Gamma"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := enigma.NewConfig(tc.rotors, tc.reflector, "AQ BW CE DR FT GZ HU", []int{3, 17, 9, 0}[:len(tc.rotors)])
+			if err != nil {
+				t.Fatalf("failed to create config: %v", err)
+			}
+			groundPositions := []int{4, 21, 8, 0}[:len(tc.rotors)]
+			ground, _ := enigma.NewState(groundPositions...)
+			messages, settings := dayTraffic(t, cfg, ground, tc.greek != nil)
+
+			result, err := Run(messages, Options{GreekWheels: tc.greek})
+			if err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+			if len(result.Alignments) < 20 || len(result.Chains) == 0 {
+				t.Fatalf("expected chains, got %d alignments and %d chains", len(result.Alignments), len(result.Chains))
+			}
+
+			// every accepted offset must be the true distance of the fast rotors
+			for _, a := range result.Alignments {
+				from, to := settings[messages[a.A].Indicator[0]], settings[messages[a.B].Indicator[0]]
+				if int(to-'A') != (int(from-'A')+a.Offset)%enigma.AlphabetSize {
+					t.Errorf("wrong offset %v for settings %c and %c", a, from, to)
+				}
+			}
+
+			chain := result.Chains[0]
+			truth := int(settings[chain.Letters[0]] - 'A')
+			if !slices.ContainsFunc(chain.Placements, func(p Placement) bool {
+				return p.Base == truth && slices.Contains(p.RightWheels, tc.rotors[0])
+			}) {
+				t.Errorf("true placement %c not among %v for chain %s", 'A'+truth, chain.Placements, chain)
+			}
+
+			if !slices.Contains(result.RightWheels, tc.rotors[0]) {
+				t.Errorf("right wheel %s not among %v", tc.rotors[0], result.RightWheels)
+			}
+			all := len(attack.WheelOrders(NavalRotors, 3)) * max(len(tc.greek), 1)
+			if len(result.WheelOrders) >= all/2 || !slices.ContainsFunc(result.WheelOrders, func(order []string) bool {
+				return slices.Equal(order, tc.rotors)
+			}) {
+				t.Errorf("expected the true wheel order among fewer than %d, got %d", all/2, len(result.WheelOrders))
+			}
+		})
+	}
+}
+
+func TestDecibans(t *testing.T) {
+	if Decibans(100, 8, GermanRepeatRate) <= 0 {
+		t.Errorf("German repeat rate should favour depth")
+	}
+	if Decibans(100, 4, GermanRepeatRate) >= 0 {
+		t.Errorf("random repeat rate should count against depth")
+	}
+	if _, err := Run([]Message{{Indicator: "AB", Text: "X"}}, Options{}); err == nil {
+		t.Errorf("expected error for a short indicator")
+	}
+}
@@ -0,0 +1,230 @@
+package banburismus
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Chain is a set of fast rotor indicator letters whose distances are known from accepted
+// offsets, written at Bletchley as a strip like "K..B.....X"
+type Chain struct {
+	Letters   []byte // sorted by distance
+	Distances []int  // positions of the fast rotor after Letters[0], 0 to 25
+
+	// true positions that survive the scritchmus and have a fitting right wheel,
+	// usually only a few
+	Placements []Placement
+}
+
+// Placement puts a chain on the alphabet
+type Placement struct {
+	// true position of the fast rotor, 0 for A, of the setting whose indicator letter is
+	// Letters[0]
+	Base int
+
+	// rotors whose turnovers fit the offsets of the chain about as well as the best
+	RightWheels []string
+}
+
+func (c Chain) String() string {
+	return strip(c.Letters, c.Distances)
+}
+
+func (p Placement) String() string {
+	return fmt.Sprintf("%c %v", 'A'+p.Base, p.RightWheels)
+}
+
+// letterChains joins fast rotor indicator letters by their distances, a union-find that
+// keeps the distance of every letter from its parent
+type letterChains struct {
+	parent   [enigma.AlphabetSize]int
+	distance [enigma.AlphabetSize]int
+	used     [enigma.AlphabetSize]bool
+}
+
+func newLetterChains() *letterChains {
+	c := &letterChains{}
+	for i := range c.parent {
+		c.parent[i] = i
+	}
+	return c
+}
+
+// returns the root of a letter and its distance from the root
+func (c *letterChains) find(x int) (int, int) {
+	if c.parent[x] == x {
+		return x, 0
+	}
+	root, d := c.find(c.parent[x])
+	c.parent[x] = root
+	c.distance[x] = (c.distance[x] + d) % enigma.AlphabetSize
+	return root, c.distance[x]
+}
+
+// records that b stands offset positions after a, reports false if that contradicts the
+// distances known so far
+func (c *letterChains) add(a, b byte, offset int) bool {
+	x, y := int(a-'A'), int(b-'A')
+	rootX, dx := c.find(x)
+	rootY, dy := c.find(y)
+	if rootX == rootY {
+		return ((dy-dx-offset)%enigma.AlphabetSize+enigma.AlphabetSize)%enigma.AlphabetSize == 0
+	}
+	c.parent[rootY] = rootX
+	c.distance[rootY] = ((dx+offset-dy)%enigma.AlphabetSize + enigma.AlphabetSize) % enigma.AlphabetSize
+	c.used[x], c.used[y] = true, true
+	return true
+}
+
+// builds the chains of at least two letters and places them
+func (c *letterChains) build(alignments []Alignment, indicators []string, rotors []string, threshold float64) []Chain {
+	members := make(map[int][]int)
+	for x := range c.parent {
+		if c.used[x] {
+			root, _ := c.find(x)
+			members[root] = append(members[root], x)
+		}
+	}
+
+	var chains []Chain
+	for root, letters := range members {
+		// start the strip after the largest gap between letters
+		slices.SortFunc(letters, func(x, y int) int {
+			_, dx := c.find(x)
+			_, dy := c.find(y)
+			return dx - dy
+		})
+		start, gap := 0, 0
+		for i, x := range letters {
+			_, d := c.find(x)
+			_, previous := c.find(letters[(i+len(letters)-1)%len(letters)])
+			if g := ((d-previous)%enigma.AlphabetSize + enigma.AlphabetSize) % enigma.AlphabetSize; g > gap {
+				start, gap = i, g
+			}
+		}
+		_, origin := c.find(letters[start])
+
+		var chain Chain
+		for i := range letters {
+			x := letters[(start+i)%len(letters)]
+			_, d := c.find(x)
+			chain.Letters = append(chain.Letters, byte('A'+x))
+			chain.Distances = append(chain.Distances, ((d-origin)%enigma.AlphabetSize+enigma.AlphabetSize)%enigma.AlphabetSize)
+		}
+
+		var links []Alignment
+		for _, alignment := range alignments {
+			if r, _ := c.find(int(indicators[alignment.A][0] - 'A')); r == root {
+				links = append(links, alignment)
+			}
+		}
+		chain.Placements = place(chain, links, indicators, rotors, threshold)
+		chains = append(chains, chain)
+	}
+
+	slices.SortFunc(chains, func(x, y Chain) int {
+		if len(x.Letters) != len(y.Letters) {
+			return len(y.Letters) - len(x.Letters)
+		}
+		return int(x.Letters[0]) - int(y.Letters[0])
+	})
+	return chains
+}
+
+// tries every true position of a chain. The fast rotor letters of all indicators were
+// enciphered at the same position of the machine, so the true setting and the indicator
+// letter are swapped by one Enigma permutation: no letter stands for itself, and if X
+// stands for Y then Y stands for X.
+//
+// Two messages are only in depth if the fast rotor does not turn over between their
+// starts, so every rotor also tells which direction of an offset is in depth. A chance
+// offset can contradict any rotor, so rotors are kept that are less than threshold
+// decibans worse than the best placed one.
+func place(chain Chain, links []Alignment, indicators []string, rotors []string, threshold float64) []Placement {
+	distance := make(map[byte]int)
+	for i, letter := range chain.Letters {
+		distance[letter] = chain.Distances[i]
+	}
+
+	type fit struct {
+		base, rotor int
+		cost        float64
+	}
+	var fits []fit
+	best := math.Inf(1)
+bases:
+	for base := 0; base < enigma.AlphabetSize; base++ {
+		position := func(letter byte) int {
+			return (base + distance[letter]) % enigma.AlphabetSize
+		}
+		for _, letter := range chain.Letters {
+			setting := byte('A' + position(letter))
+			if setting == letter {
+				continue bases
+			}
+			if _, ok := distance[setting]; ok && byte('A'+position(setting)) != letter {
+				continue bases
+			}
+		}
+
+		for i, rotorType := range rotors {
+			rotor, err := enigma.NewHistoricalRotor(rotorType)
+			if err != nil {
+				continue
+			}
+			notches := rotor.Notches()
+			turnsOver := func(from, steps int) bool {
+				for step := 0; step < steps; step++ {
+					if slices.Contains(notches, (from+step)%enigma.AlphabetSize) {
+						return true
+					}
+				}
+				return false
+			}
+
+			cost := 0.0
+			for _, link := range links {
+				start := position(indicators[link.A][0])
+				switch {
+				case !turnsOver(start, link.Offset):
+					// the direction found is in depth
+				case !turnsOver((start+link.Offset)%enigma.AlphabetSize, enigma.AlphabetSize-link.Offset):
+					cost += link.Decibans - link.Reverse
+				default:
+					cost += link.Decibans // neither direction can be in depth
+				}
+			}
+			fits = append(fits, fit{base: base, rotor: i, cost: cost})
+			best = min(best, cost)
+		}
+	}
+
+	var placements []Placement
+	for _, f := range fits {
+		if f.cost >= best+threshold {
+			continue
+		}
+		if len(placements) == 0 || placements[len(placements)-1].Base != f.base {
+			placements = append(placements, Placement{Base: f.base})
+		}
+		last := &placements[len(placements)-1]
+		last.RightWheels = append(last.RightWheels, rotors[f.rotor])
+	}
+	return placements
+}
+
+// returns the rotors that fit some placement of every chain
+func rightWheels(chains []Chain, rotors []string) []string {
+	wheels := slices.Clone(rotors)
+	for _, chain := range chains {
+		wheels = slices.DeleteFunc(wheels, func(rotor string) bool {
+			return !slices.ContainsFunc(chain.Placements, func(p Placement) bool {
+				return slices.Contains(p.RightWheels, rotor)
+			})
+		})
+	}
+	return wheels
+}
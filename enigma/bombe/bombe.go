@@ -0,0 +1,357 @@
+package bombe
+
+/*
+	Bombe simulates the Turing-Welchman Bombe with Welchman's diagonal board.
+
+	For every wheel order and every start position the bombe assumes a partner (stecker) for
+	the test letter of the menu and lets the consequences spread through the menu: if letter
+	x is steckered to y and the menu connects x to z at position i, then z is steckered to
+	S_i(y), where S_i is the scrambler (rotors and reflector) at that position. The diagonal
+	board adds the symmetry of the plugboard, x steckered to y means y steckered to x.
+
+	When the assumption is wrong, the contradictions usually light up every possible partner
+	of the test letter. The bombe stops where either exactly one partner is live (the
+	assumption is consistent) or all but one are live (the remaining one is consistent).
+
+	Like the historical machine the simulation models only the fast rotor moving along the
+	crib. Positions are reported as core positions for ring settings AAA, a turnover of the
+	middle rotor within the crib hides the correct stop.
+*/
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/internal/scramble"
+)
+
+// DefaultRotors are the rotors of the Enigma I
+var DefaultRotors = []string{"I", "II", "III", "IV", "V"}
+
+// Options control a bombe run
+type Options struct {
+	// rotors to build wheel orders from, nil uses DefaultRotors. Naval M3 rotors
+	// VI-VIII from historical.go can be added.
+	Rotors []string
+
+	// explicit wheel orders in machine order (rightmost first), overrides Rotors
+	WheelOrders [][]string
+
+	// reflector type, "" uses UKW-B
+	Reflector string
+
+	// number of goroutines, 0 uses runtime.GOMAXPROCS(0)
+	Workers int
+
+	// called after each finished unit of work with the number of positions tested so far
+	// and in total, never from two goroutines at the same time
+	Progress func(done, total int)
+}
+
+// Stop is a position where the bombe stopped
+type Stop struct {
+	Rotors    []string     // wheel order in machine order (rightmost first)
+	Reflector string       //
+	Start     enigma.State // core start position for ring settings AAA
+
+	// stecker hypotheses deduced for the menu letters, "AB" means A is steckered to B,
+	// "AA" that A is not steckered. Letters the menu cannot decide are left out.
+	Steckers []string
+}
+
+// returns the steckered pairs of the stop in the "AB CD" form accepted by NewPlugboard
+func (s Stop) Plugboard() string {
+	var pairs []string
+	for _, pair := range s.Steckers {
+		if pair[0] < pair[1] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (s Stop) String() string {
+	return fmt.Sprintf("%s %s %s [%s]", strings.Join(s.Rotors, " "), s.Reflector, s.Start, strings.Join(s.Steckers, " "))
+}
+
+// Run runs the bombe with the menu over all wheel orders and start positions. Stops are
+// returned ordered by wheel order and start position. If ctx is cancelled the run ends
+// early with the stops found so far and the context error.
+func Run(ctx context.Context, menu *Menu, opts Options) ([]Stop, error) {
+	if len(menu.Edges) == 0 {
+		return nil, fmt.Errorf("empty menu")
+	}
+	if err := menu.validate(); err != nil {
+		return nil, err
+	}
+
+	reflectorType := opts.Reflector
+	if reflectorType == "" {
+		reflectorType = "UKW-B"
+	}
+
+	wheelOrders := opts.WheelOrders
+	if wheelOrders == nil {
+		rotors := opts.Rotors
+		if rotors == nil {
+			rotors = DefaultRotors
+		}
+		wheelOrders = permutations(rotors, 3)
+	}
+
+	// build the rotors of every wheel order up front, so errors show before the run
+	scramblers := make([]*scramble.Scrambler, len(wheelOrders))
+	for i, order := range wheelOrders {
+		if len(order) != 3 {
+			return nil, fmt.Errorf("wheel order %v: the bombe needs 3 rotors", order)
+		}
+		s, err := scramble.New(order, reflectorType)
+		if err != nil {
+			return nil, err
+		}
+		scramblers[i] = s
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// a unit is a wheel order with fixed left and middle rotor positions
+	type unit struct {
+		order        int
+		left, middle int
+	}
+	const positionsPerUnit = enigma.AlphabetSize
+	total := len(wheelOrders) * enigma.AlphabetSize * enigma.AlphabetSize * positionsPerUnit
+
+	jobs := make(chan unit)
+	var (
+		mu    sync.Mutex // guards stops, done and calls to Progress
+		stops []Stop
+		done  int
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// every worker needs its own rotors, positions are changed while testing
+			local := make([]*scramble.Scrambler, len(scramblers))
+			for i, s := range scramblers {
+				local[i] = s.Clone()
+			}
+			t := newTester(menu)
+
+			for u := range jobs {
+				perms := local[u.order].FastPermutations(u.middle, u.left)
+
+				var found []Stop
+				for right := 0; right < enigma.AlphabetSize; right++ {
+					steckers, ok := t.check(&perms, right)
+					if !ok {
+						continue
+					}
+					start, _ := enigma.NewState(right, u.middle, u.left)
+					found = append(found, Stop{
+						Rotors:    append([]string(nil), wheelOrders[u.order]...),
+						Reflector: reflectorType,
+						Start:     start,
+						Steckers:  steckers,
+					})
+				}
+
+				mu.Lock()
+				stops = append(stops, found...)
+				done += positionsPerUnit
+				if opts.Progress != nil {
+					opts.Progress(done, total)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var runErr error
+feed:
+	for order := range wheelOrders {
+		for left := 0; left < enigma.AlphabetSize; left++ {
+			for middle := 0; middle < enigma.AlphabetSize; middle++ {
+				select {
+				case jobs <- unit{order, left, middle}:
+				case <-ctx.Done():
+					runErr = ctx.Err()
+					break feed
+				}
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	orderIndex := make(map[string]int, len(wheelOrders))
+	for i, order := range wheelOrders {
+		orderIndex[strings.Join(order, " ")] = i
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		a, b := orderIndex[strings.Join(stops[i].Rotors, " ")], orderIndex[strings.Join(stops[j].Rotors, " ")]
+		if a != b {
+			return a < b
+		}
+		return stateKey(stops[i].Start) < stateKey(stops[j].Start)
+	})
+	return stops, runErr
+}
+
+// orders start positions with the leftmost rotor as the most significant digit
+func stateKey(s enigma.State) int {
+	key := 0
+	positions := s.Positions()
+	for i := len(positions) - 1; i >= 0; i-- {
+		key = key*enigma.AlphabetSize + positions[i]
+	}
+	return key
+}
+
+// returns all ordered selections of k distinct items
+func permutations(items []string, k int) [][]string {
+	var result [][]string
+	var current []string
+	used := make([]bool, len(items))
+
+	var walk func()
+	walk = func() {
+		if len(current) == k {
+			result = append(result, append([]string(nil), current...))
+			return
+		}
+		for i, item := range items {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			current = append(current, item)
+			walk()
+			current = current[:len(current)-1]
+			used[i] = false
+		}
+	}
+	walk()
+	return result
+}
+
+//-------------------- tester -----------------------------
+
+// tester runs the menu through the diagonal board for one position at a time
+type tester struct {
+	menu  *Menu
+	test  int          // test register letter
+	links [][]menuLink // links[x] are the menu connections of letter x
+	live  [enigma.AlphabetSize][enigma.AlphabetSize]bool
+	queue [][2]int
+}
+
+// a connection of a letter to another letter at a ciphertext position
+type menuLink struct {
+	other    int
+	position int
+}
+
+func newTester(menu *Menu) *tester {
+	t := &tester{
+		menu:  menu,
+		test:  int(menu.TestLetter() - 'A'),
+		links: make([][]menuLink, enigma.AlphabetSize),
+	}
+	for _, e := range menu.Edges {
+		a, b := int(e.A-'A'), int(e.B-'A')
+		t.links[a] = append(t.links[a], menuLink{b, e.Position})
+		t.links[b] = append(t.links[b], menuLink{a, e.Position})
+	}
+	return t
+}
+
+// checks the start position with the fast rotor at right, perms are the scrambler
+// permutations of the fast rotor positions for the left and middle rotors. Returns the
+// stecker hypotheses if the bombe stops.
+func (t *tester) check(perms *[enigma.AlphabetSize]scramble.Permutation, right int) ([]string, bool) {
+	// assume the test letter is steckered to A and see what follows
+	t.propagate(perms, right, 0)
+
+	count, free := 0, -1
+	for partner := 0; partner < enigma.AlphabetSize; partner++ {
+		if t.live[t.test][partner] {
+			count++
+		} else {
+			free = partner
+		}
+	}
+
+	var stecker int
+	switch count {
+	case 1:
+		stecker = 0
+	case enigma.AlphabetSize - 1:
+		stecker = free
+		t.propagate(perms, right, stecker)
+	default:
+		return nil, false
+	}
+
+	// read the consequences of the consistent hypothesis for every menu letter
+	var steckers []string
+	for x := 0; x < enigma.AlphabetSize; x++ {
+		if len(t.links[x]) == 0 {
+			continue
+		}
+		partner, n := -1, 0
+		for y := 0; y < enigma.AlphabetSize; y++ {
+			if t.live[x][y] {
+				partner = y
+				n++
+			}
+		}
+		if n == 1 {
+			steckers = append(steckers, string([]byte{byte('A' + x), byte('A' + partner)}))
+		}
+	}
+	return steckers, true
+}
+
+// clears the registers and spreads the hypothesis "test letter steckered to partner"
+func (t *tester) propagate(perms *[enigma.AlphabetSize]scramble.Permutation, right int, partner int) {
+	t.live = [enigma.AlphabetSize][enigma.AlphabetSize]bool{}
+	t.queue = t.queue[:0]
+	t.activate(t.test, partner)
+
+	for len(t.queue) > 0 {
+		wire := t.queue[len(t.queue)-1]
+		t.queue = t.queue[:len(t.queue)-1]
+		x, y := wire[0], wire[1]
+
+		for _, link := range t.links[x] {
+			// the machine steps before each letter, so letter i is enciphered at right+i+1
+			perm := &perms[(right+link.position+1)%enigma.AlphabetSize]
+			t.activate(link.other, int(perm[y]))
+		}
+	}
+}
+
+// makes the wire (x, y) and its diagonal partner (y, x) live
+func (t *tester) activate(x, y int) {
+	if !t.live[x][y] {
+		t.live[x][y] = true
+		t.queue = append(t.queue, [2]int{x, y})
+	}
+	if !t.live[y][x] {
+		t.live[y][x] = true
+		t.queue = append(t.queue, [2]int{y, x})
+	}
+}
@@ -0,0 +1,121 @@
+package bombe
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestNewMenu(t *testing.T) {
+	menu, err := NewMenu("wetter", "XXABCDFG", 2)
+	if err != nil {
+		t.Fatalf("failed to create menu: %v", err)
+	}
+	if len(menu.Edges) != 6 {
+		t.Fatalf("expected 6 edges, got %d", len(menu.Edges))
+	}
+	if e := menu.Edges[0]; e.A != 'W' || e.B != 'A' || e.Position != 2 {
+		t.Errorf("unexpected first edge: %+v", e)
+	}
+	if menu.TestLetter() != 'E' && menu.TestLetter() != 'T' {
+		t.Errorf("expected E or T as test letter, got %c", menu.TestLetter())
+	}
+
+	if _, err := NewMenu("ABC", "XBZ", 0); err == nil {
+		t.Errorf("expected error for a letter enciphered to itself")
+	}
+	if _, err := NewMenu("ABCD", "XYZ", 0); err == nil {
+		t.Errorf("expected error for a crib that does not fit")
+	}
+}
+
+func TestRunFindsKey(t *testing.T) {
+	plugboard := "AQ EZ RT UO HL"
+	cfg, err := enigma.NewConfig([]string{"I", "II", "III"}, "UKW-B", plugboard, nil)
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	// the fast rotor does not reach its notch (Q) within the crib
+	start, _ := enigma.StateFromString("RAC")
+	machine, _ := cfg.NewEnigma(start)
+
+	crib := "WETTERVORHERSAGEBISKAYA"
+	ciphertext, _ := machine.Encrypt(crib)
+
+	menu, err := NewMenu(crib, ciphertext, 0)
+	if err != nil {
+		t.Fatalf("failed to create menu: %v", err)
+	}
+
+	var lastDone, lastTotal int
+	stops, err := Run(context.Background(), menu, Options{
+		WheelOrders: [][]string{{"I", "II", "III"}},
+		Workers:     4,
+		Progress: func(done, total int) {
+			lastDone, lastTotal = done, total
+		},
+	})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if lastDone != 17576 || lastTotal != 17576 {
+		t.Errorf("unexpected progress: %d/%d", lastDone, lastTotal)
+	}
+
+	var stop *Stop
+	for i := range stops {
+		if stops[i].Start == start {
+			stop = &stops[i]
+		}
+	}
+	if stop == nil {
+		t.Fatalf("no stop at %s among %d stops", start, len(stops))
+	}
+	if len(stops) > 100 {
+		t.Errorf("too many stops for a menu with loops: %d", len(stops))
+	}
+
+	pb, _ := enigma.NewPlugboard(plugboard)
+	for _, pair := range stop.Steckers {
+		if got := pb.Forward(int(pair[0] - 'A')); got != int(pair[1]-'A') {
+			t.Errorf("wrong stecker hypothesis %s", pair)
+		}
+	}
+	if len(stop.Steckers) == 0 {
+		t.Errorf("stop without stecker hypotheses")
+	}
+	if !strings.Contains(stop.String(), "I II III UKW-B RAC") {
+		t.Errorf("unexpected stop description: %s", stop)
+	}
+}
+
+func TestRunValidation(t *testing.T) {
+	menu, _ := NewMenu("WETTER", "QRSUVX", 0)
+
+	if _, err := Run(context.Background(), menu, Options{WheelOrders: [][]string{{"I", "II"}}}); err == nil {
+		t.Errorf("expected error for a wheel order with two rotors")
+	}
+	if _, err := Run(context.Background(), menu, Options{Rotors: []string{"I", "II", "IX"}}); err == nil {
+		t.Errorf("expected error for an unknown rotor")
+	}
+	if _, err := Run(context.Background(), &Menu{}, Options{}); err == nil {
+		t.Errorf("expected error for an empty menu")
+	}
+	for _, edge := range []Edge{{'a', 'B', 0}, {'A', '?', 0}, {'A', 'A', 0}, {'A', 'B', -1}} {
+		if _, err := Run(context.Background(), &Menu{Edges: []Edge{edge}}, Options{}); err == nil {
+			t.Errorf("expected error for edge %+v", edge)
+		}
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	menu, _ := NewMenu("WETTER", "QRSUVX", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, menu, Options{Workers: 2}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package bombe
+
+/*
+	A menu is the graph the bombe is wired from: every letter of a crib is connected with
+	the ciphertext letter below it, labelled with the position in the message. Closed loops
+	in this graph are what makes the bombe reject wrong positions, each loop cuts the number
+	of random stops by about a factor of 26. The functions in this file build and judge menus
+	and do not depend on running a bombe.
+*/
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Edge connects a crib letter and the ciphertext letter it enciphers to
+type Edge struct {
+	A, B     byte // the two letters, 'A'-'Z'
+	Position int  // index of the letter in the ciphertext
+}
+
+func (e Edge) String() string {
+	return fmt.Sprintf("%c-%c@%d", e.A, e.B, e.Position)
+}
+
+// Menu is the letter-connection graph of a crib placed on a ciphertext
+type Menu struct {
+	Edges []Edge
+}
+
+// builds the menu of a crib placed at offset in the ciphertext. Both are reduced to their
+// letters first. Because the Enigma never enciphers a letter to itself, a placement where
+// a crib letter meets the same ciphertext letter is impossible and reported as an error.
+func NewMenu(crib, ciphertext string, offset int) (*Menu, error) {
+	crib, ciphertext = letters(crib), letters(ciphertext)
+	if crib == "" {
+		return nil, fmt.Errorf("empty crib")
+	}
+	if offset < 0 || offset+len(crib) > len(ciphertext) {
+		return nil, fmt.Errorf("crib of length %d at offset %d does not fit a ciphertext of length %d",
+			len(crib), offset, len(ciphertext))
+	}
+
+	menu := &Menu{}
+	for i := 0; i < len(crib); i++ {
+		a, b := crib[i], ciphertext[offset+i]
+		if a == b {
+			return nil, fmt.Errorf("crib letter %c meets itself at position %d", a, offset+i)
+		}
+		menu.Edges = append(menu.Edges, Edge{A: a, B: b, Position: offset + i})
+	}
+	return menu, nil
+}
+
+// checks that every edge joins two different letters 'A'-'Z', as NewMenu builds them.
+// Menus can also be built by hand, and the bombe indexes its registers by the letters.
+func (m *Menu) validate() error {
+	for _, e := range m.Edges {
+		if e.A < 'A' || e.A > 'Z' || e.B < 'A' || e.B > 'Z' {
+			return fmt.Errorf("invalid edge %s: letters must be A-Z", e)
+		}
+		if e.A == e.B {
+			return fmt.Errorf("invalid edge %s: a letter never enciphers to itself", e)
+		}
+		if e.Position < 0 {
+			return fmt.Errorf("invalid edge %s: negative position", e)
+		}
+	}
+	return nil
+}
+
+// returns the letters of s in upper case, like attack.Letters. The attack package builds
+// on menus, so the bombe cannot import it.
+func letters(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, char := range s {
+		if char >= 'a' && char <= 'z' {
+			char = char - 'a' + 'A'
+		}
+		if char >= 'A' && char <= 'Z' {
+			sb.WriteRune(char)
+		}
+	}
+	return sb.String()
+}
+
+// BestMenu chooses the best menu a crib placement offers: among all stretches of at most
+// maxLength consecutive crib letters (0 means no limit) it takes the connected part with
+// the most loops, and of those the longest. The historical bombes could wire menus of
+// about 12 to 16 letters.
+func BestMenu(crib, ciphertext string, offset, maxLength int) (*Menu, error) {
+	full, err := NewMenu(crib, ciphertext, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(full.Edges)
+	if maxLength > 0 && maxLength < length {
+		length = maxLength
+	}
+
+	var candidates []*Menu
+	for start := 0; start+length <= len(full.Edges); start++ {
+		window := &Menu{Edges: full.Edges[start : start+length]}
+		candidates = append(candidates, window.Components()...)
+	}
+	return bestComponent(candidates), nil
+}
+
+// returns the menu with the most loops, of those the longest, the first one on ties
+func bestComponent(menus []*Menu) *Menu {
+	var best *Menu
+	bestLoops := -1
+	for _, menu := range menus {
+		loops := menu.LoopCount()
+		if loops > bestLoops || (loops == bestLoops && len(menu.Edges) > len(best.Edges)) {
+			best, bestLoops = menu, loops
+		}
+	}
+	return best
+}
+
+// returns the number of edges at each letter
+func (m *Menu) degrees() [enigma.AlphabetSize]int {
+	var degrees [enigma.AlphabetSize]int
+	for _, e := range m.Edges {
+		degrees[e.A-'A']++
+		degrees[e.B-'A']++
+	}
+	return degrees
+}
+
+// returns the letters of the menu in alphabetical order
+func (m *Menu) Letters() string {
+	var letters []byte
+	for i, d := range m.degrees() {
+		if d > 0 {
+			letters = append(letters, byte('A'+i))
+		}
+	}
+	return string(letters)
+}
+
+// Components splits the menu into its connected parts, in the order of their first edge
+func (m *Menu) Components() []*Menu {
+	var parent [enigma.AlphabetSize]int
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	for _, e := range m.Edges {
+		parent[find(int(e.A-'A'))] = find(int(e.B - 'A'))
+	}
+
+	var components []*Menu
+	index := map[int]int{} // root letter -> component
+	for _, e := range m.Edges {
+		root := find(int(e.A - 'A'))
+		i, ok := index[root]
+		if !ok {
+			i = len(components)
+			index[root] = i
+			components = append(components, &Menu{})
+		}
+		components[i].Edges = append(components[i].Edges, e)
+	}
+	return components
+}
+
+// returns the number of independent loops: edges - letters + connected components
+func (m *Menu) LoopCount() int {
+	return len(m.Edges) - len(m.Letters()) + len(m.Components())
+}
+
+// Loops returns a set of independent loops (closures) of the menu. Each loop is the list
+// of edges leading from a letter back to itself.
+func (m *Menu) Loops() [][]Edge {
+	// grow a spanning forest, every edge left over closes a loop with the forest path
+	// between its letters
+	var (
+		parentEdge [enigma.AlphabetSize]int // edge leading to the letter, -1 for roots
+		depth      [enigma.AlphabetSize]int
+		seen       [enigma.AlphabetSize]bool
+		inTree     = make([]bool, len(m.Edges))
+	)
+	adjacent := make([][]int, enigma.AlphabetSize)
+	for i, e := range m.Edges {
+		adjacent[e.A-'A'] = append(adjacent[e.A-'A'], i)
+		adjacent[e.B-'A'] = append(adjacent[e.B-'A'], i)
+	}
+
+	for _, e := range m.Edges {
+		root := int(e.A - 'A')
+		if seen[root] {
+			continue
+		}
+		seen[root], parentEdge[root] = true, -1
+		queue := []int{root}
+		for len(queue) > 0 {
+			x := queue[0]
+			queue = queue[1:]
+			for _, i := range adjacent[x] {
+				y := other(m.Edges[i], x)
+				if seen[y] {
+					continue
+				}
+				seen[y], parentEdge[y], depth[y], inTree[i] = true, i, depth[x]+1, true
+				queue = append(queue, y)
+			}
+		}
+	}
+
+	var loops [][]Edge
+	for i, e := range m.Edges {
+		if inTree[i] {
+			continue
+		}
+
+		// walk both letters up to their common ancestor
+		var up, down []Edge
+		a, b := int(e.A-'A'), int(e.B-'A')
+		for a != b {
+			if depth[a] >= depth[b] {
+				up = append(up, m.Edges[parentEdge[a]])
+				a = other(m.Edges[parentEdge[a]], a)
+			} else {
+				down = append(down, m.Edges[parentEdge[b]])
+				b = other(m.Edges[parentEdge[b]], b)
+			}
+		}
+		slices.Reverse(up)
+
+		// from the common ancestor down to e.A, across e and back up from e.B
+		loop := append(up, e)
+		loop = append(loop, down...)
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// returns the letter at the other end of the edge
+func other(e Edge, letter int) int {
+	if int(e.A-'A') == letter {
+		return int(e.B - 'A')
+	}
+	return int(e.A - 'A')
+}
+
+// returns the bombe's test register: the letter with the most connections in the part of
+// the menu with the most loops
+func (m *Menu) TestLetter() byte {
+	best := bestComponent(m.Components())
+	if best == nil {
+		return 'A'
+	}
+
+	degrees := best.degrees()
+	letter := 0
+	for i, d := range degrees {
+		if d > degrees[letter] {
+			letter = i
+		}
+	}
+	return byte('A' + letter)
+}
+
+// ExpectedFalseStops estimates the number of random stops per wheel order. Without loops
+// every one of the 26^3 positions can stop, each loop of the tested part of the menu
+// divides that by 26.
+func (m *Menu) ExpectedFalseStops() float64 {
+	loops := 0
+	if best := bestComponent(m.Components()); best != nil {
+		loops = best.LoopCount()
+	}
+	positions := math.Pow(enigma.AlphabetSize, 3)
+	return positions / math.Pow(enigma.AlphabetSize, float64(loops))
+}
+
+// Analysis summarises the quality of a menu
+type Analysis struct {
+	Letters            int     // distinct letters
+	Edges              int     // crib letters
+	Components         int     // connected parts
+	Loops              int     // independent loops over all parts
+	TestLetter         byte    //
+	ExpectedFalseStops float64 // per wheel order
+}
+
+// Analyze describes the menu
+func (m *Menu) Analyze() Analysis {
+	return Analysis{
+		Letters:            len(m.Letters()),
+		Edges:              len(m.Edges),
+		Components:         len(m.Components()),
+		Loops:              m.LoopCount(),
+		TestLetter:         m.TestLetter(),
+		ExpectedFalseStops: m.ExpectedFalseStops(),
+	}
+}
+
+func (m *Menu) String() string {
+	edges := make([]string, len(m.Edges))
+	for i, e := range m.Edges {
+		edges[i] = e.String()
+	}
+	return strings.Join(edges, " ")
+}
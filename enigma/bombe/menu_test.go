@@ -0,0 +1,70 @@
+package bombe
+
+import (
+	"testing"
+)
+
+func TestMenuLoops(t *testing.T) {
+	// A-B, B-C, C-A is a loop, C-D a tail and X-Y a separate part
+	menu := &Menu{Edges: []Edge{
+		{'A', 'B', 0}, {'B', 'C', 1}, {'C', 'D', 2}, {'C', 'A', 3}, {'X', 'Y', 4},
+	}}
+
+	if got := menu.LoopCount(); got != 1 {
+		t.Errorf("expected 1 loop, got %d", got)
+	}
+	if got := len(menu.Components()); got != 2 {
+		t.Errorf("expected 2 components, got %d", got)
+	}
+	if got := menu.TestLetter(); got != 'C' {
+		t.Errorf("expected test letter C, got %c", got)
+	}
+
+	loops := menu.Loops()
+	if len(loops) != 1 || len(loops[0]) != 3 {
+		t.Fatalf("expected one loop of 3 edges, got %v", loops)
+	}
+	// in a closed loop every letter is met by an even number of edges
+	counts := map[byte]int{}
+	for _, e := range loops[0] {
+		counts[e.A]++
+		counts[e.B]++
+	}
+	for letter, n := range counts {
+		if n%2 != 0 {
+			t.Errorf("loop %v does not close at %c", loops[0], letter)
+		}
+	}
+
+	analysis := menu.Analyze()
+	if analysis.Letters != 6 || analysis.Edges != 5 || analysis.Components != 2 || analysis.Loops != 1 {
+		t.Errorf("unexpected analysis: %+v", analysis)
+	}
+	if analysis.ExpectedFalseStops != 676 {
+		t.Errorf("expected 676 false stops, got %v", analysis.ExpectedFalseStops)
+	}
+}
+
+func TestBestMenu(t *testing.T) {
+	// the first three letters form a loop A-B, B-C, C-A, the rest only trees
+	crib := "ABCDEFGH"
+	ciphertext := "BCAMNOPQ"
+
+	menu, err := BestMenu(crib, ciphertext, 0, 0)
+	if err != nil {
+		t.Fatalf("best menu failed: %v", err)
+	}
+	if menu.LoopCount() != 1 || len(menu.Edges) != 3 {
+		t.Errorf("expected the loop of 3 edges, got %s", menu)
+	}
+
+	// a window of 2 letters cannot hold the loop, the first two letters are chosen
+	menu, _ = BestMenu(crib, ciphertext, 0, 2)
+	if menu.LoopCount() != 0 || len(menu.Edges) != 2 {
+		t.Errorf("expected 2 edges without loops, got %s", menu)
+	}
+
+	if _, err := BestMenu("ABC", "ABC", 0, 0); err == nil {
+		t.Errorf("expected error for an impossible placement")
+	}
+}
@@ -174,18 +174,27 @@ func (b *Builder) Build() (*Enigma, error) {
 		b.plugboard, _ = NewPlugboard("")
 	}
 
+	// work on copies, so neither the caller's custom components nor machines built
+	// earlier by this builder are changed
+	rotors := make([]*Rotor, len(b.rotors))
+	for i, rotor := range b.rotors {
+		rotors[i] = rotor.Clone()
+	}
+	reflector := *b.reflector
+	plugboard := *b.plugboard
+
 	//apply the ring settings
 	if len(b.ringSettings) > 0 {
-		if len(b.ringSettings) != len(b.rotors) {
+		if len(b.ringSettings) != len(rotors) {
 			return nil, fmt.Errorf("number of ring settings (%d) must match number of rotors (%d)",
-				len(b.ringSettings), len(b.rotors))
+				len(b.ringSettings), len(rotors))
 		}
 		for i, setting := range b.ringSettings {
-			b.rotors[i].SetRingSetting(setting)
+			rotors[i].SetRingSetting(setting)
 		}
 	}
 
-	enigma := NewEnigma(b.rotors, b.reflector, b.plugboard)
+	enigma := NewEnigma(rotors, &reflector, &plugboard)
 
 	// apply the rotor positions
 	if len(b.rotorPositions) > 0 {
@@ -195,3 +204,17 @@ func (b *Builder) Build() (*Enigma, error) {
 	}
 	return enigma, nil
 }
+
+// returns the immutable configuration and the start state of the machine the builder describes
+func (b *Builder) Config() (Config, State, error) {
+	machine, err := b.Build()
+	if err != nil {
+		return Config{}, State{}, err
+	}
+
+	cfg, err := machine.Config()
+	if err != nil {
+		return Config{}, State{}, err
+	}
+	return cfg, machine.State(), nil
+}
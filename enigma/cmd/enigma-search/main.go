@@ -0,0 +1,188 @@
+package main
+
+/*
+	enigma-search runs a key search of the search package split over several local
+	processes.
+
+	Usage:
+		enigma-search coordinate [flags] [ciphertext file]   hand out work, print the results
+		enigma-search work [flags]                            search units for a coordinator
+
+	The coordinator listens on a unix socket and saves its progress to a checkpoint file
+	after every unit. Started again with the same checkpoint and no ciphertext it resumes
+	the saved search. With -spawn it starts local workers itself, more can join at any
+	time with "enigma-search work".
+*/
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma/search"
+)
+
+const usage = `usage: enigma-search <command> [flags]
+
+commands:
+  coordinate   hand out the units of a search and print the results
+  work         search units for a coordinator
+
+run "enigma-search <command> -h" for the flags of a command
+`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("enigma-search: ")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "coordinate":
+		err = coordinate(ctx, os.Args[2:])
+	case "work":
+		err = work(ctx, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func coordinate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("coordinate", flag.ExitOnError)
+	socket := fs.String("socket", "enigma-search.sock", "unix socket to listen on")
+	checkpoint := fs.String("checkpoint", "enigma-search.json", "checkpoint file")
+	crib := fs.String("crib", "", "known plaintext, without it positions are scored by index of coincidence")
+	offset := fs.Int("offset", 0, "position of the crib in the ciphertext")
+	rotors := fs.String("rotors", "I II III IV V", "rotor types to build wheel orders from")
+	reflector := fs.String("reflector", "UKW-B", "reflector type")
+	plugboard := fs.String("plugboard", "", `known plugboard connections, e.g. "AB CD"`)
+	rings := fs.Bool("rings", false, "also search the ring settings of the fast and middle rotor")
+	keep := fs.Int("keep", 10, "number of best results kept")
+	spawn := fs.Int("spawn", 0, "number of local worker processes to start")
+	fs.Parse(args)
+
+	var c *search.Coordinator
+	var err error
+	if fs.NArg() == 0 {
+		if c, err = search.Resume(*checkpoint); err != nil {
+			return fmt.Errorf("no ciphertext given and no checkpoint to resume: %w", err)
+		}
+	} else {
+		ciphertext, err := readFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		c, err = search.NewCoordinator(search.Job{
+			Ciphertext:  ciphertext,
+			Crib:        *crib,
+			CribOffset:  *offset,
+			Rotors:      strings.Fields(*rotors),
+			Reflector:   *reflector,
+			Plugboard:   *plugboard,
+			SearchRings: *rings,
+			Keep:        *keep,
+		}, *checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	// a socket left behind by a coordinator that was killed
+	os.Remove(*socket)
+	listener, err := net.Listen("unix", *socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(*socket)
+
+	for i := 0; i < *spawn; i++ {
+		if err := spawnWorker(ctx, *socket); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				done, total := c.Progress()
+				log.Printf("%d of %d units done", done, total)
+			case <-c.Finished():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	done, total := c.Progress()
+	log.Printf("listening on %s, %d of %d units done", *socket, done, total)
+	serveErr := c.Serve(ctx, listener)
+	for _, result := range c.Results() {
+		fmt.Println(result)
+	}
+	if errors.Is(serveErr, context.Canceled) {
+		log.Printf("stopped, run again with -checkpoint %s to resume", *checkpoint)
+		return nil
+	}
+	return serveErr
+}
+
+func work(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("work", flag.ExitOnError)
+	socket := fs.String("socket", "enigma-search.sock", "unix socket of the coordinator")
+	workers := fs.Int("workers", 0, "goroutines per unit, 0 uses all CPUs")
+	fs.Parse(args)
+
+	err := search.Work(ctx, "unix", *socket, search.WorkerOptions{Workers: *workers})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// starts this program as a worker process that ends with ctx
+func spawnWorker(ctx context.Context, socket string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, executable, "work", "-socket", socket, "-workers", "1")
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// reads a file, "-" is stdin
+func readFile(name string) (string, error) {
+	if name == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(name)
+	return string(data), err
+}
@@ -0,0 +1,52 @@
+package main
+
+/*
+	enigma-server serves the Enigma HTTP JSON API of the server package.
+
+	Usage:
+		enigma-server -addr :8080 -session-ttl 30m
+*/
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	ttl := flag.Duration("session-ttl", server.DefaultSessionTTL, "sessions expire after this long without a request")
+	maxSessions := flag.Int("max-sessions", server.DefaultMaxSessions, "number of sessions kept at the same time")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	srv := server.New(server.Options{SessionTTL: *ttl, MaxSessions: *maxSessions})
+	go srv.RunJanitor(ctx, time.Minute)
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("enigma-server listening on %s", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+/*
+	enigma-sim is an interactive terminal simulator of the Enigma machine.
+
+	It shows the rotor windows, a QWERTZ lampboard and the plugboard. Typing a letter
+	encrypts it and lights its lamp, the digit keys turn the wheels by hand and ':' opens a
+	command line to change the settings while the machine is running.
+
+	Usage:
+		enigma-sim -rotors "I II III" -reflector UKW-B -plugboard "AB CD" -rings AAA -positions AAA
+
+	Rotors, rings and positions are given in machine order (rightmost rotor first), the same
+	order Builder.WithRotors uses.
+*/
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func main() {
+	rotors := flag.String("rotors", "I II III", "rotor types in machine order (rightmost first)")
+	reflector := flag.String("reflector", "UKW-B", "reflector type")
+	plugboard := flag.String("plugboard", "", `plugboard connections, e.g. "AB CD EF"`)
+	rings := flag.String("rings", "", "ring settings as letters, e.g. AAA")
+	positions := flag.String("positions", "", "start positions as letters, e.g. AAA")
+	flag.Parse()
+
+	sim, err := newSimulator(strings.Fields(*rotors), *reflector, *plugboard, *rings, *positions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "enigma-sim:", err)
+		os.Exit(1)
+	}
+
+	raw := enableRawMode()
+	if raw {
+		defer disableRawMode()
+	}
+
+	if err := run(sim, os.Stdin, os.Stdout, raw); err != nil {
+		if raw {
+			disableRawMode()
+		}
+		fmt.Fprintln(os.Stderr, "enigma-sim:", err)
+		os.Exit(1)
+	}
+}
+
+// reads keys until the user quits, redrawing the screen after every key
+func run(sim *simulator, in io.Reader, out io.Writer, raw bool) error {
+	reader := bufio.NewReader(in)
+	draw(sim, out, raw)
+
+	for !sim.quit {
+		key, err := reader.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if key == keyEscape && skipEscapeSequence(reader) {
+			// arrow and function keys send sequences starting with Esc, only a bare Esc quits
+			continue
+		}
+
+		sim.handleKey(key)
+		draw(sim, out, raw)
+	}
+	return nil
+}
+
+// discards the rest of a terminal escape sequence after an Esc and reports whether there
+// was one. The bytes of a sequence arrive together, a bare Esc has nothing buffered after it.
+func skipEscapeSequence(reader *bufio.Reader) bool {
+	if reader.Buffered() == 0 {
+		return false
+	}
+	next, err := reader.Peek(1)
+	if err != nil || (next[0] != '[' && next[0] != 'O') {
+		return false
+	}
+	reader.ReadByte()
+
+	// parameters and intermediates are followed by a final byte in '@'..'~'
+	for reader.Buffered() > 0 {
+		b, err := reader.ReadByte()
+		if err != nil || (b >= '@' && b <= '~') {
+			break
+		}
+	}
+	return true
+}
+
+// clears the terminal and draws the simulator
+func draw(sim *simulator, out io.Writer, raw bool) {
+	view := sim.view()
+	if raw {
+		// raw mode disables the translation of \n to \r\n
+		view = strings.ReplaceAll(view, "\n", "\r\n")
+	}
+	fmt.Fprint(out, "\x1b[H\x1b[2J"+view)
+}
+
+// switches the terminal to unbuffered input without echo, so every key arrives at once.
+// Without stty (e.g. on Windows) the simulator still works, keys arrive after Enter.
+func enableRawMode() bool {
+	return stty("raw", "-echo") == nil
+}
+
+func disableRawMode() {
+	stty("-raw", "echo")
+}
+
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
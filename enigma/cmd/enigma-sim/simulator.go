@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// rows of the keyboard and the lampboard of the German Enigma (QWERTZ)
+var keyboardRows = []string{
+	"QWERTZUIO",
+	"ASDFGHJK",
+	"PYXCVBNML",
+}
+
+// key codes handled by the simulator
+const (
+	keyCtrlC     = 3
+	keyBackspace = 8
+	keyEnter     = 13
+	keyNewline   = 10
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// shifted digit keys on a US keyboard, used to turn a wheel backwards
+const shiftedDigits = "!@#$"
+
+// simulator holds the state of the interactive machine and turns keypresses into changes
+type simulator struct {
+	rotors    []string // rotor types in machine order (rightmost first)
+	reflector string
+	plugboard string
+	rings     string
+	start     string // rotor positions the current message started with
+
+	machine *enigma.Enigma
+	input   strings.Builder
+	output  strings.Builder
+	lamp    rune // the lit lamp, 0 if none
+
+	commandMode bool
+	command     []byte
+	status      string
+	quit        bool
+}
+
+// creates a simulator with the given settings, rings and positions are strings like "AAA"
+func newSimulator(rotors []string, reflector, plugboard, rings, positions string) (*simulator, error) {
+	s := &simulator{
+		rotors:    rotors,
+		reflector: reflector,
+		plugboard: plugboard,
+		rings:     rings,
+		start:     positions,
+	}
+	if err := s.rebuild(positions); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// builds a new machine from the current settings at the given positions
+func (s *simulator) rebuild(positions string) error {
+	if len(s.rotors) != 3 && len(s.rotors) != enigma.MaxRotors {
+		return fmt.Errorf("a machine needs 3 rotors, or %d for the M4, got %d", enigma.MaxRotors, len(s.rotors))
+	}
+	if positions == "" {
+		positions = strings.Repeat("A", len(s.rotors))
+	}
+	rings := s.rings
+	if rings == "" {
+		rings = strings.Repeat("A", len(s.rotors))
+	}
+
+	machine, err := enigma.NewBuilder().
+		WithRotors(s.rotors...).
+		WithReflector(s.reflector).
+		WithPlugboard(s.plugboard).
+		WithRingSettingsFromString(rings).
+		WithRotorPositionsFromString(positions).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	s.machine = machine
+	s.lamp = 0
+	return nil
+}
+
+// returns the current rotor positions as letters in machine order
+func (s *simulator) windows() string {
+	var sb strings.Builder
+	for _, pos := range s.machine.GetRotorPositions() {
+		sb.WriteRune(rune(pos + 'A'))
+	}
+	return sb.String()
+}
+
+// handles a single keypress
+func (s *simulator) handleKey(key byte) {
+	if s.commandMode {
+		s.handleCommandKey(key)
+		return
+	}
+
+	switch {
+	case key == keyCtrlC || key == keyEscape:
+		s.quit = true
+	case key == ':':
+		s.commandMode = true
+		s.command = s.command[:0]
+		s.status = ""
+	case key == ' ':
+		s.input.WriteByte(' ')
+		s.output.WriteByte(' ')
+	case key >= '1' && key <= '9':
+		s.turnWheel(int(key-'1'), 1)
+	case strings.IndexByte(shiftedDigits, key) >= 0:
+		s.turnWheel(strings.IndexByte(shiftedDigits, key), -1)
+	case (key >= 'A' && key <= 'Z') || (key >= 'a' && key <= 'z'):
+		s.press(rune(key))
+	}
+}
+
+// encrypts a letter and lights its lamp
+func (s *simulator) press(key rune) {
+	lamp, err := s.machine.EncryptChar(key)
+	if err != nil {
+		s.status = err.Error()
+		return
+	}
+	s.input.WriteString(strings.ToUpper(string(key)))
+	s.output.WriteRune(lamp)
+	s.lamp = lamp
+	s.status = ""
+}
+
+// turns wheel i (machine order) by delta positions, like turning it by hand
+func (s *simulator) turnWheel(i int, delta int) {
+	positions := s.machine.GetRotorPositions()
+	if i >= len(positions) {
+		return
+	}
+	positions[i] = (positions[i] + delta + enigma.AlphabetSize) % enigma.AlphabetSize
+	s.machine.SetRotorPositions(positions...)
+	s.lamp = 0
+}
+
+func (s *simulator) handleCommandKey(key byte) {
+	switch key {
+	case keyEnter, keyNewline:
+		s.commandMode = false
+		s.runCommand(string(s.command))
+	case keyEscape, keyCtrlC:
+		s.commandMode = false
+	case keyBackspace, keyDelete:
+		if len(s.command) > 0 {
+			s.command = s.command[:len(s.command)-1]
+		}
+	default:
+		if key >= ' ' && key < keyDelete {
+			s.command = append(s.command, key)
+		}
+	}
+}
+
+// executes a settings command such as "rotors I II III" or "plug AB CD"
+func (s *simulator) runCommand(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	name, args := strings.ToLower(fields[0]), fields[1:]
+
+	// keep the settings to restore them if the new ones are invalid
+	rotors, reflector, plugboard, rings, start := s.rotors, s.reflector, s.plugboard, s.rings, s.start
+	positions := s.windows()
+	clearTapes := false
+
+	switch name {
+	case "rotors":
+		if len(args) != len(s.rotors) {
+			// a different number of rotors needs new rings and positions
+			s.rings, positions = "", ""
+		}
+		s.rotors = args
+	case "reflector":
+		if len(args) != 1 {
+			s.status = "usage: reflector UKW-B"
+			return
+		}
+		s.reflector = args[0]
+	case "plug":
+		s.plugboard = strings.Join(args, " ")
+	case "rings":
+		s.rings = strings.Join(args, "")
+	case "pos":
+		positions = strings.Join(args, "")
+		s.start = positions
+		clearTapes = true
+	case "reset":
+		positions = s.start
+		clearTapes = true
+	case "quit", "q":
+		s.quit = true
+		return
+	default:
+		s.status = fmt.Sprintf("unknown command: %s", name)
+		return
+	}
+
+	if err := s.rebuild(positions); err != nil {
+		s.rotors, s.reflector, s.plugboard, s.rings, s.start = rotors, reflector, plugboard, rings, start
+		s.status = err.Error()
+		return
+	}
+	if name == "rotors" && positions == "" {
+		s.start = s.windows()
+	}
+	if clearTapes {
+		s.input.Reset()
+		s.output.Reset()
+	}
+	s.status = "ok"
+}
+
+// renders the simulator, lit lamps are highlighted with ANSI colors
+func (s *simulator) view() string {
+	var sb strings.Builder
+
+	sb.WriteString("ENIGMA\n\n")
+	fmt.Fprintf(&sb, "Reflector %s   Rotors (rightmost first) %s   Rings %s\n\n",
+		s.reflector, strings.Join(s.rotors, " "), s.ringsOrDefault())
+
+	// rotor windows with the hotkeys that turn them
+	sb.WriteString("Windows  ")
+	for _, pos := range s.windows() {
+		fmt.Fprintf(&sb, "[%c] ", pos)
+	}
+	sb.WriteString("\nHotkeys  ")
+	for i := range s.rotors {
+		fmt.Fprintf(&sb, "%d/%c ", i+1, shiftedKey(i))
+	}
+	sb.WriteString("\n\n")
+
+	// lampboard
+	for i, row := range keyboardRows {
+		sb.WriteString(strings.Repeat("  ", i%2) + "  ")
+		for _, lamp := range row {
+			if lamp == s.lamp {
+				fmt.Fprintf(&sb, "\x1b[30;43m %c \x1b[0m ", lamp)
+			} else {
+				fmt.Fprintf(&sb, " %c  ", lamp)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	plugboard := s.machine.Plugboard().String()
+	if plugboard == "" {
+		plugboard = "none"
+	}
+	fmt.Fprintf(&sb, "\nPlugboard %s\n\n", plugboard)
+	fmt.Fprintf(&sb, "Input   %s\nOutput  %s\n\n", s.input.String(), s.output.String())
+
+	if s.commandMode {
+		fmt.Fprintf(&sb, ":%s\n", s.command)
+	} else {
+		sb.WriteString("letters encrypt, 1-9 turn a wheel forward, shift+digit backward, ':' command, Esc quits\n")
+		sb.WriteString("commands: rotors I II III | reflector UKW-B | plug AB CD | rings AAA | pos AAA | reset | quit\n")
+	}
+	if s.status != "" {
+		sb.WriteString(s.status + "\n")
+	}
+	return sb.String()
+}
+
+func (s *simulator) ringsOrDefault() string {
+	if s.rings == "" {
+		return strings.Repeat("A", len(s.rotors))
+	}
+	return s.rings
+}
+
+// returns the shifted digit that turns wheel i backwards, or '-' if there is none
+func shiftedKey(i int) rune {
+	if i < len(shiftedDigits) {
+		return rune(shiftedDigits[i])
+	}
+	return '-'
+}
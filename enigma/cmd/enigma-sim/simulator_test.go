@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func newTestSimulator(t *testing.T) *simulator {
+	sim, err := newSimulator([]string{"I", "II", "III"}, "UKW-B", "AB CD", "AAA", "AAA")
+	if err != nil {
+		t.Fatalf("failed to create simulator: %v", err)
+	}
+	return sim
+}
+
+func typeKeys(sim *simulator, keys string) {
+	for i := 0; i < len(keys); i++ {
+		sim.handleKey(keys[i])
+	}
+}
+
+func TestSimulatorTypingLightsLamps(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	machine, _ := enigma.NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		WithPlugboard("AB CD").
+		Build()
+	want, _ := machine.Encrypt("HELLO")
+
+	typeKeys(sim, "hello")
+
+	if got := sim.output.String(); got != want {
+		t.Errorf("output %s, want %s", got, want)
+	}
+	if sim.lamp != rune(want[len(want)-1]) {
+		t.Errorf("lit lamp %c, want %c", sim.lamp, want[len(want)-1])
+	}
+	if !strings.Contains(sim.view(), "Output  "+want) {
+		t.Errorf("view does not show the output tape:\n%s", sim.view())
+	}
+}
+
+func TestSimulatorTurnWheels(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	typeKeys(sim, "113!")
+	if got := sim.windows(); got != "BAB" {
+		t.Errorf("windows %s, want BAB", got)
+	}
+
+	typeKeys(sim, "@")
+	if got := sim.windows(); got != "BZB" {
+		t.Errorf("windows %s, want BZB", got)
+	}
+}
+
+func TestSimulatorCommands(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	typeKeys(sim, ":plug QW ER\r")
+	if got := sim.machine.Plugboard().String(); got != "ER QW" {
+		t.Errorf("plugboard %s, want ER QW", got)
+	}
+
+	typeKeys(sim, ":pos XYZ\r")
+	if got := sim.windows(); got != "XYZ" {
+		t.Errorf("windows %s, want XYZ", got)
+	}
+
+	typeKeys(sim, "abc:reset\r")
+	if got := sim.windows(); got != "XYZ" || sim.output.Len() != 0 {
+		t.Errorf("reset did not restore the start: windows %s output %q", got, sim.output.String())
+	}
+
+	// invalid settings are rejected and the old ones kept
+	typeKeys(sim, ":rotors I II IX\r")
+	if !strings.Contains(sim.status, "invalid rotor type") || sim.rotors[2] != "III" {
+		t.Errorf("invalid rotors were accepted: status %q rotors %v", sim.status, sim.rotors)
+	}
+
+	typeKeys(sim, ":rotors I\r")
+	if !strings.Contains(sim.status, "3 rotors") || len(sim.rotors) != 3 {
+		t.Errorf("a single rotor was accepted: status %q rotors %v", sim.status, sim.rotors)
+	}
+	typeKeys(sim, "a")
+	if sim.lamp == 0 {
+		t.Errorf("the old machine does not encrypt after a rejected rotor change")
+	}
+
+	typeKeys(sim, ":quit\r")
+	if !sim.quit {
+		t.Errorf("quit command did not quit")
+	}
+}
+
+func TestSimulatorIgnoresEscapeSequences(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	// up arrow, F5 and right arrow in application mode
+	if err := run(sim, strings.NewReader("\x1b[A\x1b[15~\x1bOCab"), io.Discard, false); err != nil {
+		t.Fatal(err)
+	}
+	if sim.quit || sim.input.String() != "AB" {
+		t.Errorf("escape sequences were not ignored: quit %v input %q", sim.quit, sim.input.String())
+	}
+
+	if err := run(sim, strings.NewReader("\x1b"), io.Discard, false); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.quit {
+		t.Errorf("a bare Esc did not quit")
+	}
+}
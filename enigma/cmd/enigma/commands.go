@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/diagram"
+)
+
+// creates the flag set of a command, parse errors are reported as usage errors
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("enigma "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parses the flags, -h is not an error
+func parseFlags(fs *flag.FlagSet, args []string) (bool, error) {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return false, nil
+	}
+	if err != nil {
+		return false, usageError{err.Error()}
+	}
+	return true, nil
+}
+
+// encrypts or decrypts the files given as arguments, or stdin if there are none
+func cryptCommand(name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name, stderr)
+	var cfg machineConfig
+	cfg.register(fs)
+	group := fs.Int("group", 0, "write the output in groups of n letters, 0 keeps the layout of the input")
+	width := fs.Int("width", 10, "groups per line when grouping, 0 writes a single line")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if *group < 0 || *width < 0 {
+		return usageError{"-group and -width must not be negative"}
+	}
+
+	machine, err := cfg.build()
+	if err != nil {
+		return configError{err}
+	}
+
+	out := bufio.NewWriter(stdout)
+	var writer outputWriter
+	if *group > 0 {
+		writer = &groupWriter{w: out, size: *group, width: *width}
+	} else {
+		writer = &lineWriter{w: out}
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, file := range files {
+		if err := cryptFile(machine, file, stdin, writer.writeLine); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.flush(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// runs every line of a file through the machine, "-" reads stdin
+func cryptFile(machine *enigma.Enigma, file string, stdin io.Reader, write func(string) error) error {
+	in := stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			result, cryptErr := machine.Encrypt(strings.TrimRight(line, "\r\n"))
+			if cryptErr != nil {
+				return cryptErr
+			}
+			if writeErr := write(result); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// outputWriter formats the processed lines
+type outputWriter interface {
+	writeLine(line string) error
+	flush() error
+}
+
+// lineWriter keeps the line layout of the input
+type lineWriter struct {
+	w io.Writer
+}
+
+func (lw *lineWriter) writeLine(line string) error {
+	_, err := fmt.Fprintln(lw.w, line)
+	return err
+}
+
+func (lw *lineWriter) flush() error {
+	return nil
+}
+
+// groupWriter writes letters in groups of size, width groups per line
+type groupWriter struct {
+	w       io.Writer
+	size    int
+	width   int
+	current strings.Builder
+	groups  int // groups written so far
+}
+
+func (gw *groupWriter) writeLine(line string) error {
+	for _, char := range line {
+		if char == ' ' {
+			continue
+		}
+		gw.current.WriteRune(char)
+		if gw.current.Len() == gw.size {
+			if err := gw.writeGroup(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (gw *groupWriter) writeGroup() error {
+	sep := " "
+	switch {
+	case gw.groups == 0:
+		sep = ""
+	case gw.width > 0 && gw.groups%gw.width == 0:
+		sep = "\n"
+	}
+
+	_, err := io.WriteString(gw.w, sep+gw.current.String())
+	gw.current.Reset()
+	gw.groups++
+	return err
+}
+
+func (gw *groupWriter) flush() error {
+	if gw.current.Len() > 0 {
+		if err := gw.writeGroup(); err != nil {
+			return err
+		}
+	}
+	if gw.groups > 0 {
+		_, err := io.WriteString(gw.w, "\n")
+		return err
+	}
+	return nil
+}
+
+// prints a random key in the form accepted by -config
+func keygenCommand(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("keygen", stderr)
+	pool := fs.String("pool", "I II III IV V", "rotor types to choose the wheel order from")
+	count := fs.Int("count", 3, "number of rotors (3, or 4 for the M4)")
+	reflector := fs.String("reflector", "UKW-B", "reflector type, an M4 key uses its thin version")
+	greek := fs.String("greek", "Beta Gamma", "Greek wheels to choose the fourth wheel of an M4 key from")
+	pairs := fs.Int("pairs", 10, "number of plugboard pairs (0-13)")
+	seed := fs.Uint64("seed", 0, "seed for reproducible keys, 0 uses a random seed")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+
+	rotors := strings.Fields(*pool)
+	greekWheels := strings.Fields(*greek)
+	if *count < 3 || *count > enigma.MaxRotors {
+		return usageError{fmt.Sprintf("-count must be between 3 and %d", enigma.MaxRotors)}
+	}
+	if len(rotors) < 3 {
+		return usageError{"-pool needs at least 3 rotors"}
+	}
+	if *count == enigma.MaxRotors {
+		// the M4 adds a Greek wheel left of the three rotors and needs a thin reflector
+		if len(greekWheels) == 0 {
+			return usageError{"-greek needs at least one wheel for an M4 key"}
+		}
+		switch *reflector {
+		case "UKW-B", "UKW-C":
+			*reflector += "-thin"
+		case "UKW-B-thin", "UKW-C-thin":
+		default:
+			return usageError{fmt.Sprintf("reflector %s does not fit the M4", *reflector)}
+		}
+	}
+	if *pairs < 0 || *pairs > enigma.AlphabetSize/2 {
+		return usageError{fmt.Sprintf("-pairs must be between 0 and %d", enigma.AlphabetSize/2)}
+	}
+
+	if *seed == 0 {
+		var buf [8]byte
+		if _, err := crand.Read(buf[:]); err != nil {
+			return err
+		}
+		*seed = binary.LittleEndian.Uint64(buf[:])
+	}
+	rng := rand.New(rand.NewPCG(*seed, *seed>>32))
+
+	// wheel order without repeated rotors
+	rng.Shuffle(len(rotors), func(i, j int) { rotors[i], rotors[j] = rotors[j], rotors[i] })
+	rotors = rotors[:3]
+	if *count == enigma.MaxRotors {
+		rotors = append(rotors, greekWheels[rng.IntN(len(greekWheels))])
+	}
+
+	letters := []byte("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	rng.Shuffle(len(letters), func(i, j int) { letters[i], letters[j] = letters[j], letters[i] })
+	plugs := make([]string, *pairs)
+	for i := range plugs {
+		plugs[i] = string(letters[2*i : 2*i+2])
+	}
+
+	randomLetters := func() string {
+		b := make([]byte, *count)
+		for i := range b {
+			b[i] = byte('A' + rng.IntN(enigma.AlphabetSize))
+		}
+		return string(b)
+	}
+
+	cfg := machineConfig{
+		rotors:    strings.Join(rotors, " "),
+		reflector: *reflector,
+		plugboard: strings.Join(plugs, " "),
+		rings:     randomLetters(),
+		positions: randomLetters(),
+	}
+
+	// make sure the key is usable, e.g. the rotor types in the pool exist
+	if _, err := cfg.build(); err != nil {
+		return configError{err}
+	}
+
+	_, err := fmt.Fprintln(stdout, cfg.String())
+	return err
+}
+
+// describes the configured machine, optionally with the signal path of a key
+func infoCommand(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("info", stderr)
+	var cfg machineConfig
+	cfg.register(fs)
+	key := fs.String("key", "", "draw the signal path of this key")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if len(*key) > 1 {
+		return usageError{"-key must be a single letter"}
+	}
+
+	machine, err := cfg.build()
+	if err != nil {
+		return configError{err}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Rotors (rightmost first):\n")
+	for i, rotor := range machine.Rotors() {
+		notches := rotor.Notches()
+		notchLetters := make([]byte, len(notches))
+		for j, notch := range notches {
+			notchLetters[j] = byte('A' + notch)
+		}
+		fmt.Fprintf(&sb, "  %d  %-5s %s  notches %-2s  ring %c  position %c\n",
+			i+1, rotor.Name, rotor.Wiring(), notchLetters,
+			rune('A'+rotor.RingSetting()), rune('A'+rotor.Position()))
+	}
+
+	reflector := machine.Reflector()
+	fmt.Fprintf(&sb, "Reflector: %s %s\n", reflector.Name(), reflector.Wiring())
+
+	plugboard := machine.Plugboard().String()
+	if plugboard == "" {
+		plugboard = "none"
+	}
+	fmt.Fprintf(&sb, "Plugboard: %s\n", plugboard)
+	fmt.Fprintf(&sb, "Config:    %s\n", cfg.String())
+
+	if _, err := io.WriteString(stdout, sb.String()); err != nil {
+		return err
+	}
+
+	if *key != "" {
+		fmt.Fprintln(stdout)
+		if err := diagram.ASCII(stdout, machine, rune((*key)[0])); err != nil {
+			return usageError{err.Error()}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// machineConfig holds the settings of a machine as given on the command line.
+// Rotors, rings and positions are in machine order (rightmost rotor first),
+// the same order Builder.WithRotors uses.
+type machineConfig struct {
+	rotors    string // e.g. "I II III"
+	reflector string
+	plugboard string
+	rings     string
+	positions string
+}
+
+// registers the machine flags on fs, -config fills the same settings from a single string
+func (c *machineConfig) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.rotors, "rotors", "I II III", "rotor types in machine order (rightmost first)")
+	fs.StringVar(&c.reflector, "reflector", "UKW-B", "reflector type")
+	fs.StringVar(&c.plugboard, "plugboard", "", `plugboard connections, e.g. "AB CD EF"`)
+	fs.StringVar(&c.rings, "rings", "", "ring settings as letters, e.g. AAA")
+	fs.StringVar(&c.positions, "positions", "", "start positions as letters, e.g. AAA")
+	fs.Func("config", `all settings in one string, e.g. "rotors=I II III; reflector=UKW-B; plugboard=AB CD; rings=AAA; positions=AAA"`,
+		c.parse)
+}
+
+// parses a config string of "key=value" settings separated by ';'
+func (c *machineConfig) parse(s string) error {
+	for _, setting := range strings.Split(s, ";") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return fmt.Errorf("invalid setting %q, expected key=value", setting)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "rotors":
+			c.rotors = value
+		case "reflector":
+			c.reflector = value
+		case "plugboard":
+			c.plugboard = value
+		case "rings":
+			c.rings = value
+		case "positions":
+			c.positions = value
+		default:
+			return fmt.Errorf("unknown setting %q", key)
+		}
+	}
+	return nil
+}
+
+// formats the config in the form accepted by -config
+func (c *machineConfig) String() string {
+	return fmt.Sprintf("rotors=%s; reflector=%s; plugboard=%s; rings=%s; positions=%s",
+		c.rotors, c.reflector, c.plugboard, c.rings, c.positions)
+}
+
+// builds the machine described by the config
+func (c *machineConfig) build() (*enigma.Enigma, error) {
+	rotors := strings.Fields(c.rotors)
+	if len(rotors) != 3 && len(rotors) != enigma.MaxRotors {
+		return nil, fmt.Errorf("a machine needs 3 rotors, or %d for the M4, got %d", enigma.MaxRotors, len(rotors))
+	}
+
+	builder := enigma.NewBuilder().
+		WithRotors(rotors...).
+		WithReflector(c.reflector).
+		WithPlugboard(c.plugboard)
+
+	if c.rings != "" {
+		builder = builder.WithRingSettingsFromString(c.rings)
+	}
+	if c.positions != "" {
+		builder = builder.WithRotorPositionsFromString(c.positions)
+	}
+	return builder.Build()
+}
@@ -0,0 +1,138 @@
+package main
+
+/*
+	enigma is a command line tool for the Enigma machine.
+
+	Usage:
+		enigma encrypt [flags] [file ...]   encrypt files or stdin
+		enigma decrypt [flags] [file ...]   decrypt files or stdin
+		enigma keygen  [flags]              print a random key as a config string
+		enigma info    [flags]              describe the configured machine
+
+	The machine is configured with -rotors, -reflector, -plugboard, -rings and -positions,
+	or with all of them at once using -config. Rotors, rings and positions are given in
+	machine order (rightmost rotor first), the order Builder.WithRotors uses.
+
+	Exit codes:
+		0  success
+		1  reading or writing failed
+		2  invalid usage
+		3  invalid rotor type
+		4  invalid reflector type
+		5  any other invalid machine configuration
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+const (
+	exitOK               = 0
+	exitIOError          = 1
+	exitUsage            = 2
+	exitInvalidRotor     = 3
+	exitInvalidReflector = 4
+	exitInvalidConfig    = 5
+)
+
+// usageError marks errors caused by wrong arguments
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+// configError marks errors caused by an invalid machine configuration
+type configError struct {
+	err error
+}
+
+func (e configError) Error() string {
+	return e.err.Error()
+}
+
+func (e configError) Unwrap() error {
+	return e.err
+}
+
+const usage = `usage: enigma <command> [flags]
+
+commands:
+  encrypt   encrypt files or stdin
+  decrypt   decrypt files or stdin
+  keygen    print a random key as a config string
+  info      describe the configured machine
+
+run "enigma <command> -h" for the flags of a command
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// runs the command given by args and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "encrypt", "decrypt":
+		// the machine is reciprocal, both commands do the same
+		err = cryptCommand(args[0], args[1:], stdin, stdout, stderr)
+	case "keygen":
+		err = keygenCommand(args[1:], stdout, stderr)
+	case "info":
+		err = infoCommand(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		err = usageError{fmt.Sprintf("unknown command %q", args[0])}
+	}
+
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintln(stderr, "enigma:", err)
+
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprint(stderr, usage)
+	}
+	return exitCode(err)
+}
+
+// maps an error to the exit code of the tool
+func exitCode(err error) int {
+	var (
+		usageErr     usageError
+		rotorErr     enigma.ErrInvalidRotorType
+		reflectorErr enigma.ErrInvalidReflectorType
+		configErr    configError
+	)
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &rotorErr):
+		return exitInvalidRotor
+	case errors.As(err, &reflectorErr):
+		return exitInvalidReflector
+	case errors.As(err, &configErr):
+		return exitInvalidConfig
+	default:
+		return exitIOError
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func runTool(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	config := "rotors=I II III; reflector=UKW-B; plugboard=AB CD; rings=BCD; positions=XYZ"
+
+	code, ciphertext, stderr := runTool(t, "HELLO WORLD\nsecond line\n", "encrypt", "-config", config)
+	if code != exitOK {
+		t.Fatalf("encrypt failed with %d: %s", code, stderr)
+	}
+
+	machine, _ := enigma.NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		WithPlugboard("AB CD").
+		WithRingSettingsFromString("BCD").
+		WithRotorPositionsFromString("XYZ").
+		Build()
+	first, _ := machine.Encrypt("HELLO WORLD")
+	second, _ := machine.Encrypt("SECOND LINE")
+	if ciphertext != first+"\n"+second+"\n" {
+		t.Errorf("unexpected ciphertext %q", ciphertext)
+	}
+
+	code, plaintext, stderr := runTool(t, ciphertext, "decrypt", "-config", config)
+	if code != exitOK {
+		t.Fatalf("decrypt failed with %d: %s", code, stderr)
+	}
+	if plaintext != "HELLO WORLD\nSECOND LINE\n" {
+		t.Errorf("unexpected plaintext %q", plaintext)
+	}
+}
+
+func TestEncryptGrouping(t *testing.T) {
+	code, out, stderr := runTool(t, "ATTACK AT DAWN\nNOW\n", "encrypt", "-group", "5", "-width", "2")
+	if code != exitOK {
+		t.Fatalf("encrypt failed with %d: %s", code, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 || len(lines[0]) != 11 || len(lines[1]) != 5 {
+		t.Errorf("unexpected grouping %q", out)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{}, exitUsage},
+		{[]string{"frobnicate"}, exitUsage},
+		{[]string{"encrypt", "-nosuchflag"}, exitUsage},
+		{[]string{"encrypt", "-rotors", "I II IX"}, exitInvalidRotor},
+		{[]string{"encrypt", "-reflector", "UKW-Z"}, exitInvalidReflector},
+		{[]string{"encrypt", "-plugboard", "AB AC"}, exitInvalidConfig},
+		{[]string{"encrypt", "-positions", "AA"}, exitInvalidConfig},
+		{[]string{"encrypt", "-rotors", "I"}, exitInvalidConfig},
+		{[]string{"encrypt", "-rotors", "I II III IV V"}, exitInvalidConfig},
+		{[]string{"encrypt", "-config", "rotors=I II"}, exitInvalidConfig},
+		{[]string{"encrypt", "-config", "colour=red"}, exitUsage},
+		{[]string{"encrypt", "does-not-exist.txt"}, exitIOError},
+		{[]string{"keygen", "-pool", "I II XI"}, exitInvalidRotor},
+		{[]string{"keygen", "-count", "2"}, exitUsage},
+		{[]string{"keygen", "-count", "4", "-pool", "I II"}, exitUsage},
+		{[]string{"keygen", "-count", "4", "-reflector", "UKW-A"}, exitUsage},
+		{[]string{"keygen", "-count", "4", "-greek", ""}, exitUsage},
+		{[]string{"info", "-key", "?"}, exitUsage},
+		{[]string{"encrypt", "-h"}, exitOK},
+	}
+
+	for _, tt := range tests {
+		code, _, _ := runTool(t, "", tt.args...)
+		if code != tt.want {
+			t.Errorf("%v: exit code %d, want %d", tt.args, code, tt.want)
+		}
+	}
+}
+
+func TestKeygenProducesUsableConfig(t *testing.T) {
+	code, out, stderr := runTool(t, "", "keygen", "-seed", "42", "-pairs", "10")
+	if code != exitOK {
+		t.Fatalf("keygen failed with %d: %s", code, stderr)
+	}
+
+	_, again, _ := runTool(t, "", "keygen", "-seed", "42", "-pairs", "10")
+	if out != again {
+		t.Errorf("keygen with the same seed differs: %q vs %q", out, again)
+	}
+
+	var cfg machineConfig
+	if err := cfg.parse(strings.TrimSpace(out)); err != nil {
+		t.Fatalf("keygen output does not parse: %v", err)
+	}
+	machine, err := cfg.build()
+	if err != nil {
+		t.Fatalf("keygen output does not build: %v", err)
+	}
+	if pairs := machine.Plugboard().Pairs(); len(pairs) != 10 {
+		t.Errorf("expected 10 plugboard pairs, got %v", pairs)
+	}
+}
+
+func TestInfoShowsConfig(t *testing.T) {
+	code, out, stderr := runTool(t, "", "info", "-rotors", "III II I", "-plugboard", "AQ", "-key", "H")
+	if code != exitOK {
+		t.Fatalf("info failed with %d: %s", code, stderr)
+	}
+	for _, want := range []string{"BDFHJLCPRTXVZNYEIWGAKMUSQO", "UKW-B", "Plugboard: AQ", "H -> "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("info output misses %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestKeygenM4(t *testing.T) {
+	code, out, stderr := runTool(t, "", "keygen", "-seed", "7", "-count", "4")
+	if code != exitOK {
+		t.Fatalf("keygen failed with %d: %s", code, stderr)
+	}
+
+	var cfg machineConfig
+	if err := cfg.parse(strings.TrimSpace(out)); err != nil {
+		t.Fatalf("keygen output does not parse: %v", err)
+	}
+	rotors := strings.Fields(cfg.rotors)
+	if len(rotors) != 4 || (rotors[3] != "Beta" && rotors[3] != "Gamma") {
+		t.Errorf("expected a Greek wheel as the fourth wheel, got %v", rotors)
+	}
+	if cfg.reflector != "UKW-B-thin" {
+		t.Errorf("expected the thin reflector, got %s", cfg.reflector)
+	}
+	if _, err := cfg.build(); err != nil {
+		t.Errorf("keygen output does not build: %v", err)
+	}
+}
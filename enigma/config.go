@@ -0,0 +1,302 @@
+package enigma
+
+/*
+	Config and State split a machine into its immutable settings and its mutable rotor
+	positions.
+
+	A Config holds everything that stays fixed for a day's key: the rotors with their wiring
+	and notches, the ring settings, the reflector and the plugboard. It is a plain value,
+	can be shared between goroutines without locking and compared with ==. A State holds only
+	the rotor positions. Creating a machine from the two is cheap, which keeps searches over
+	thousands of configurations fast:
+
+		cfg, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "AB CD", []int{0, 0, 0})
+		state, _ := StateFromString("AAA")
+		machine, _ := cfg.NewEnigma(state)
+*/
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxRotors is the largest number of rotors a Config can hold (the naval M4)
+const MaxRotors = 4
+
+// rotorSpec is the comparable description of a rotor without its position
+type rotorSpec struct {
+	name    string
+	wiring  [AlphabetSize]uint8
+	notches uint32 // bit i is set if position i is a turnover notch
+}
+
+// reflectorSpec is the comparable description of a reflector
+type reflectorSpec struct {
+	name   string
+	wiring [AlphabetSize]uint8
+}
+
+// Config is an immutable machine configuration: wheel order, ring settings, reflector and
+// plugboard. The zero value is not a valid configuration.
+type Config struct {
+	rotorCount int
+	rotors     [MaxRotors]rotorSpec // machine order (rightmost first)
+	rings      [MaxRotors]int
+	reflector  reflectorSpec
+	plugboard  Plugboard
+}
+
+// creates a configuration from historical rotor and reflector types.
+// rotors and rings are in machine order (rightmost first), nil rings means all A.
+func NewConfig(rotors []string, reflector string, plugboard string, rings []int) (Config, error) {
+	machineRotors := make([]*Rotor, len(rotors))
+	for i, rotorType := range rotors {
+		rotor, err := NewHistoricalRotor(rotorType)
+		if err != nil {
+			return Config{}, err
+		}
+		machineRotors[i] = rotor
+	}
+
+	ref, err := NewHistoricalReflector(reflector)
+	if err != nil {
+		return Config{}, err
+	}
+
+	pb, err := NewPlugboard(plugboard)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg, err := newConfig(machineRotors, ref, pb)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if rings != nil {
+		return cfg.WithRingSettings(rings...)
+	}
+	return cfg, nil
+}
+
+// creates a configuration from machine components, the ring settings are taken from the rotors
+func newConfig(rotors []*Rotor, reflector *Reflector, plugboard *Plugboard) (Config, error) {
+	if len(rotors) < 3 || len(rotors) > MaxRotors {
+		return Config{}, fmt.Errorf("a configuration needs 3 to %d rotors, got %d", MaxRotors, len(rotors))
+	}
+
+	cfg := Config{rotorCount: len(rotors)}
+	for i, rotor := range rotors {
+		spec := rotorSpec{name: rotor.Name}
+		for j, out := range rotor.wiring {
+			spec.wiring[j] = uint8(out)
+		}
+		for _, notch := range rotor.notches {
+			spec.notches |= 1 << notch
+		}
+		cfg.rotors[i] = spec
+		cfg.rings[i] = rotor.ringSetting
+	}
+
+	cfg.reflector.name = reflector.name
+	for j, out := range reflector.wiring {
+		cfg.reflector.wiring[j] = uint8(out)
+	}
+
+	if plugboard != nil {
+		cfg.plugboard = *plugboard
+	} else {
+		cfg.plugboard = identityPlugboard()
+	}
+	return cfg, nil
+}
+
+// returns the configuration of the machine, custom rotors and reflectors included
+func (e *Enigma) Config() (Config, error) {
+	return newConfig(e.rotors, e.reflector, e.plugboard)
+}
+
+// returns a copy of the configuration with different ring settings (machine order)
+func (c Config) WithRingSettings(rings ...int) (Config, error) {
+	if len(rings) != c.rotorCount {
+		return Config{}, fmt.Errorf("number of ring settings (%d) must match number of rotors (%d)",
+			len(rings), c.rotorCount)
+	}
+
+	for i, ring := range rings {
+		if ring < 0 || ring >= AlphabetSize {
+			return Config{}, fmt.Errorf("invalid ring setting: %d", ring)
+		}
+		c.rings[i] = ring
+	}
+	return c, nil
+}
+
+// returns a copy of the configuration with a different plugboard, e.g. "AB CD EF"
+func (c Config) WithPlugboard(connections string) (Config, error) {
+	pb, err := NewPlugboard(connections)
+	if err != nil {
+		return Config{}, err
+	}
+
+	c.plugboard = *pb
+	return c, nil
+}
+
+// returns the number of rotors
+func (c Config) RotorCount() int {
+	return c.rotorCount
+}
+
+// returns the rotor identifiers in machine order (rightmost first)
+func (c Config) Rotors() []string {
+	names := make([]string, c.rotorCount)
+	for i := range names {
+		names[i] = c.rotors[i].name
+	}
+	return names
+}
+
+// returns the ring settings in machine order (rightmost first)
+func (c Config) RingSettings() []int {
+	return append([]int(nil), c.rings[:c.rotorCount]...)
+}
+
+// returns the reflector identifier
+func (c Config) Reflector() string {
+	return c.reflector.name
+}
+
+// returns the plugboard connections in the "AB CD EF" form
+func (c Config) Plugboard() string {
+	return c.plugboard.connections()
+}
+
+// formats the configuration, e.g. "I II III / UKW-B / rings AAA / AB CD"
+func (c Config) String() string {
+	var rings strings.Builder
+	for _, ring := range c.RingSettings() {
+		rings.WriteRune(rune(ring + 'A'))
+	}
+
+	plugboard := c.Plugboard()
+	if plugboard == "" {
+		plugboard = "no plugs"
+	}
+	return fmt.Sprintf("%s / %s / rings %s / %s",
+		strings.Join(c.Rotors(), " "), c.reflector.name, rings.String(), plugboard)
+}
+
+// creates a machine with this configuration at the given rotor positions
+func (c Config) NewEnigma(s State) (*Enigma, error) {
+	if c.rotorCount == 0 {
+		return nil, fmt.Errorf("invalid configuration: no rotors")
+	}
+	if s.count != c.rotorCount {
+		return nil, fmt.Errorf("expected %d positions, got %d", c.rotorCount, s.count)
+	}
+
+	rotors := make([]*Rotor, c.rotorCount)
+	for i := range rotors {
+		spec := &c.rotors[i]
+		rotor := &Rotor{
+			Name:        spec.name,
+			position:    s.positions[i],
+			ringSetting: c.rings[i],
+		}
+		for j, out := range spec.wiring {
+			rotor.wiring[j] = int(out)
+			rotor.wiringRev[out] = j
+		}
+		for notch := 0; notch < AlphabetSize; notch++ {
+			if spec.notches&(1<<notch) != 0 {
+				rotor.notches = append(rotor.notches, notch)
+			}
+		}
+		rotors[i] = rotor
+	}
+
+	ref := &Reflector{name: c.reflector.name}
+	for j, out := range c.reflector.wiring {
+		ref.wiring[j] = int(out)
+	}
+
+	pb := c.plugboard
+	return NewEnigma(rotors, ref, &pb), nil
+}
+
+//-------------------- State -----------------------------
+
+// State holds the rotor positions of a machine. It is a small comparable value.
+type State struct {
+	count     int
+	positions [MaxRotors]int // machine order (rightmost first)
+}
+
+// creates a state from rotor positions (0-25) in machine order
+func NewState(positions ...int) (State, error) {
+	if len(positions) > MaxRotors {
+		return State{}, fmt.Errorf("at most %d positions are supported, got %d", MaxRotors, len(positions))
+	}
+
+	s := State{count: len(positions)}
+	for i, pos := range positions {
+		if pos < 0 || pos >= AlphabetSize {
+			return State{}, fmt.Errorf("invalid rotor position: %d", pos)
+		}
+		s.positions[i] = pos
+	}
+	return s, nil
+}
+
+// creates a state from letters like "AAA", one letter per rotor in machine order
+func StateFromString(positions string) (State, error) {
+	values := make([]int, 0, len(positions))
+	for _, char := range strings.ToUpper(positions) {
+		if char < 'A' || char > 'Z' {
+			return State{}, fmt.Errorf("invalid position character: %c", char)
+		}
+		values = append(values, int(char-'A'))
+	}
+	return NewState(values...)
+}
+
+// returns the rotor positions in machine order
+func (s State) Positions() []int {
+	return append([]int(nil), s.positions[:s.count]...)
+}
+
+// returns the positions as letters, e.g. "AAA"
+func (s State) String() string {
+	return indexesToString(s.positions[:s.count])
+}
+
+// returns the current rotor positions as a State, only the first MaxRotors rotors are included
+func (e *Enigma) State() State {
+	s := State{count: min(len(e.rotors), MaxRotors)}
+	for i := 0; i < s.count; i++ {
+		s.positions[i] = e.rotors[i].position
+	}
+	return s
+}
+
+// sets all rotor positions from a State
+func (e *Enigma) SetState(s State) error {
+	if s.count != len(e.rotors) {
+		return fmt.Errorf("expected %d positions, got %d", len(e.rotors), s.count)
+	}
+
+	for i, rotor := range e.rotors {
+		rotor.position = s.positions[i]
+	}
+	return nil
+}
+
+// returns a plugboard without connections
+func identityPlugboard() Plugboard {
+	var pb Plugboard
+	for i := range pb.wiring {
+		pb.wiring[i] = i
+	}
+	return pb
+}
@@ -0,0 +1,139 @@
+package enigma
+
+import "testing"
+
+func TestConfigMatchesBuilder(t *testing.T) {
+	builder := NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		WithPlugboard("AB CD EF").
+		WithRingSettingsFromString("BCD").
+		WithRotorPositionsFromString("XYZ")
+
+	cfg, state, err := builder.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+
+	direct, err := NewConfig([]string{"I", "II", "III"}, "UKW-B", "EF CD AB", []int{1, 2, 3})
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	if cfg != direct {
+		t.Errorf("configs differ: %v vs %v", cfg, direct)
+	}
+	if state.String() != "XYZ" {
+		t.Errorf("unexpected state %s", state)
+	}
+
+	machine, _ := builder.Build()
+	want, _ := machine.Encrypt("THEQUICKBROWNFOX")
+
+	fromConfig, err := cfg.NewEnigma(state)
+	if err != nil {
+		t.Fatalf("failed to create machine: %v", err)
+	}
+	got, _ := fromConfig.Encrypt("THEQUICKBROWNFOX")
+	if got != want {
+		t.Errorf("machine from config encrypts %s, want %s", got, want)
+	}
+}
+
+func TestConfigComparison(t *testing.T) {
+	a, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "", nil)
+	b, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "", []int{0, 0, 0})
+	if a != b {
+		t.Errorf("equal configs compare unequal")
+	}
+
+	c, err := a.WithRingSettings(0, 0, 1)
+	if err != nil {
+		t.Fatalf("failed to change rings: %v", err)
+	}
+	if a == c {
+		t.Errorf("configs with different rings compare equal")
+	}
+	if a.RingSettings()[2] != 0 {
+		t.Errorf("WithRingSettings changed the original config")
+	}
+
+	d, _ := a.WithPlugboard("AB")
+	if a == d || d.Plugboard() != "AB" {
+		t.Errorf("unexpected plugboard change: %v", d)
+	}
+
+	seen := map[Config]bool{a: true}
+	if !seen[b] {
+		t.Errorf("configs cannot be used as map keys")
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	if _, err := NewConfig([]string{"I", "II"}, "UKW-B", "", nil); err == nil {
+		t.Errorf("expected error for two rotors")
+	}
+	if _, err := NewConfig([]string{"I", "II", "IX"}, "UKW-B", "", nil); err == nil {
+		t.Errorf("expected error for invalid rotor")
+	}
+	if _, err := NewConfig([]string{"I", "II", "III"}, "UKW-B", "", []int{0, 0}); err == nil {
+		t.Errorf("expected error for wrong number of rings")
+	}
+
+	cfg, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "", nil)
+	state, _ := StateFromString("AA")
+	if _, err := cfg.NewEnigma(state); err == nil {
+		t.Errorf("expected error for wrong number of positions")
+	}
+	if _, err := StateFromString("A1A"); err == nil {
+		t.Errorf("expected error for invalid position")
+	}
+}
+
+func TestEnigmaState(t *testing.T) {
+	cfg, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "", nil)
+	start, _ := StateFromString("ADU")
+
+	machine, _ := cfg.NewEnigma(start)
+	first, _ := machine.Encrypt("HELLO")
+	if machine.State() == start {
+		t.Errorf("state did not change while encrypting")
+	}
+
+	if err := machine.SetState(start); err != nil {
+		t.Fatalf("failed to set state: %v", err)
+	}
+	again, _ := machine.Encrypt("HELLO")
+	if first != again {
+		t.Errorf("encryption after SetState differs: %s vs %s", again, first)
+	}
+}
+
+func TestBuildDoesNotModifyCustomRotors(t *testing.T) {
+	rotors := make([]*Rotor, 3)
+	for i, name := range []string{"I", "II", "III"} {
+		rotors[i], _ = NewHistoricalRotor(name)
+	}
+	ref, _ := NewHistoricalReflector("UKW-B")
+
+	builder := NewBuilder().
+		WithCustomRotors(rotors...).
+		WithCustomReflector(ref).
+		WithRingSettings(5, 6, 7)
+
+	first, err := builder.Build()
+	if err != nil {
+		t.Fatalf("failed to build enigma: %v", err)
+	}
+	for _, rotor := range rotors {
+		if rotor.RingSetting() != 0 {
+			t.Errorf("Build changed the ring setting of rotor %s", rotor.Name)
+		}
+	}
+
+	// machines from the same builder must not share rotors
+	second, _ := builder.Build()
+	first.Encrypt("AAAA")
+	if second.GetRotorPositions()[0] != 0 {
+		t.Errorf("machines built by the same builder share rotors")
+	}
+}
@@ -0,0 +1,126 @@
+package diagram
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// ASCII writes a plain text diagram of the machine to w. If key is not 0 the contacts its
+// signal passes are marked: '<' on the way to the reflector, '>' on the way back and '*'
+// where both directions use the same contact. The keyboard column shows the pressed key
+// as 'K' and the lit lamp as 'L'.
+func ASCII(w io.Writer, m *enigma.Enigma, key rune) error {
+	l, err := newLayout(m, key)
+	if err != nil {
+		return err
+	}
+
+	marks := make([][2][enigma.AlphabetSize]byte, len(l.columns))
+	for _, p := range l.path {
+		mark := &marks[p.column][p.side][p.row]
+		switch {
+		case p.column == l.keyboard && p.back:
+			*mark = 'L'
+		case p.column == l.keyboard:
+			*mark = 'K'
+		case *mark != 0:
+			*mark = '*'
+		case p.back:
+			*mark = '>'
+		default:
+			*mark = '<'
+		}
+	}
+
+	widths := make([]int, len(l.columns))
+	for i, col := range l.columns {
+		widths[i] = max(5, len(col.label))
+		for _, detail := range col.details {
+			widths[i] = max(widths[i], len(detail))
+		}
+	}
+
+	var sb strings.Builder
+
+	// header with labels and details
+	depth := 0
+	for _, col := range l.columns {
+		depth = max(depth, len(col.details))
+	}
+	for line := -1; line < depth; line++ {
+		sb.WriteString("   ")
+		for i, col := range l.columns {
+			text := ""
+			if line < 0 {
+				text = col.label
+			} else if line < len(col.details) {
+				text = col.details[line]
+			}
+			fmt.Fprintf(&sb, " %-*s", widths[i], text)
+		}
+		sb.WriteString("\n")
+	}
+
+	// one line per contact
+	for row := 0; row < enigma.AlphabetSize; row++ {
+		fmt.Fprintf(&sb, " %c ", rune(row+'A'))
+		for i := range l.columns {
+			cell := "[" + contact(marks[i][left][row]) + " " + contact(marks[i][right][row]) + "]"
+			if i == 0 {
+				// the reflector only has contacts on the rotor side
+				cell = "[  " + contact(marks[i][right][row]) + "]"
+			}
+			if i == l.keyboard {
+				cell = "  " + contact(marks[i][left][row])
+			}
+			fmt.Fprintf(&sb, " %-*s", widths[i], cell)
+		}
+		sb.WriteString("\n")
+	}
+
+	if summary := l.summary(); summary != "" {
+		sb.WriteString("\n" + summary + "\n")
+		for _, p := range stageLines(l) {
+			sb.WriteString("  " + p + "\n")
+		}
+	}
+
+	// the padding of the last column is not needed
+	lines := strings.Split(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	_, err = io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// formats a contact mark, idle contacts are shown as '.'
+func contact(mark byte) string {
+	if mark == 0 {
+		return "."
+	}
+	return string(mark)
+}
+
+// lists the path as "component  in -> out" lines
+func stageLines(l *layout) []string {
+	var lines []string
+	for i := 1; i+1 < len(l.path)-1; i += 2 {
+		in, out := l.path[i], l.path[i+1]
+		name := l.columns[in.column].label
+		switch in.column {
+		case 0:
+			name = "reflector " + name
+		case l.keyboard - 1:
+			name = "plugboard"
+		default:
+			name = "rotor " + name
+		}
+		lines = append(lines, fmt.Sprintf("%-16s %c -> %c", name, rune(in.row+'A'), rune(out.row+'A')))
+	}
+	return lines
+}
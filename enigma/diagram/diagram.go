@@ -0,0 +1,136 @@
+package diagram
+
+/*
+	Diagram renders the state of an Enigma machine and the lit signal path of a keypress,
+	either as SVG for training material or as plain ASCII for terminals.
+
+	The machine is drawn left to right the way the signal sees it from the keyboard:
+
+		reflector | left rotor ... right rotor | plugboard | keyboard/lamps
+
+	Every component has a column of 26 contacts on each side (A at the top). The signal of
+	the key enters on the right, travels leftwards to the reflector and comes back to the
+	lamp. Rendering never changes the machine, the keypress is traced on a copy.
+*/
+
+import (
+	"fmt"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+const (
+	left  = 0
+	right = 1
+)
+
+// column is a single component of the drawing
+type column struct {
+	label   string   // short component name, e.g. "II"
+	details []string // extra lines shown under the label, e.g. "pos D"
+}
+
+// point is a contact on one side of a column that the signal passes
+type point struct {
+	column int
+	side   int
+	row    int
+	back   bool // true once the signal returns from the reflector
+}
+
+// layout holds everything both renderers need
+type layout struct {
+	columns  []column
+	path     []point // empty when no key is pressed
+	key      rune
+	lamp     rune
+	keyboard int // index of the keyboard column
+}
+
+// builds the layout of the machine, tracing key if it is not 0
+func newLayout(m *enigma.Enigma, key rune) (*layout, error) {
+	rotors := m.Rotors()
+	reflector := m.Reflector()
+	plugboard := m.Plugboard()
+
+	var trace enigma.Trace
+	if key != 0 {
+		var err error
+		if trace, err = m.Clone().EncryptCharTrace(key); err != nil {
+			return nil, err
+		}
+		// show the rotors in the position the key was encrypted with
+		for i, rotor := range rotors {
+			rotor.SetPosition(trace.PositionsAfter[i])
+		}
+	}
+
+	l := &layout{}
+	l.columns = append(l.columns, column{label: reflector.Name()})
+	for i := len(rotors) - 1; i >= 0; i-- {
+		rotor := rotors[i]
+		l.columns = append(l.columns, column{
+			label: rotor.Name,
+			details: []string{
+				"pos " + string(rune(rotor.Position()+'A')),
+				"ring " + string(rune(rotor.RingSetting()+'A')),
+			},
+		})
+	}
+
+	pairs := plugboard.Pairs()
+	if len(pairs) == 0 {
+		pairs = []string{"none"}
+	}
+	l.columns = append(l.columns, column{label: "PB", details: pairs})
+	l.columns = append(l.columns, column{label: "KEY"})
+	l.keyboard = len(l.columns) - 1
+
+	if key == 0 {
+		return l, nil
+	}
+
+	// column index of rotor i (0 = rightmost rotor)
+	rotorColumn := func(i int) int {
+		return len(rotors) - i
+	}
+	plugboardColumn := len(rotors) + 1
+
+	l.key = trace.Input
+	l.lamp = trace.Output
+	l.path = append(l.path,
+		point{l.keyboard, left, int(trace.Input - 'A'), false},
+		point{plugboardColumn, right, trace.PlugboardIn.In, false},
+		point{plugboardColumn, left, trace.PlugboardIn.Out, false},
+	)
+	for _, stage := range trace.Forward {
+		l.path = append(l.path,
+			point{rotorColumn(stage.Index), right, stage.In, false},
+			point{rotorColumn(stage.Index), left, stage.Out, false},
+		)
+	}
+	l.path = append(l.path,
+		point{0, right, trace.Reflector.In, false},
+		point{0, right, trace.Reflector.Out, true},
+	)
+	for _, stage := range trace.Backward {
+		l.path = append(l.path,
+			point{rotorColumn(stage.Index), left, stage.In, true},
+			point{rotorColumn(stage.Index), right, stage.Out, true},
+		)
+	}
+	l.path = append(l.path,
+		point{plugboardColumn, left, trace.PlugboardOut.In, true},
+		point{plugboardColumn, right, trace.PlugboardOut.Out, true},
+		point{l.keyboard, left, int(trace.Output - 'A'), true},
+	)
+	return l, nil
+}
+
+// returns a one line summary such as "H -> Q" or "" when no key is pressed
+func (l *layout) summary() string {
+	if len(l.path) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%c -> %c", l.key, l.lamp)
+}
@@ -0,0 +1,75 @@
+package diagram
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func newMachine(t *testing.T) *enigma.Enigma {
+	machine, err := enigma.NewBuilder().
+		WithRotors("III", "II", "I").
+		WithReflector("UKW-B").
+		WithPlugboard("AQ EP").
+		WithRotorPositionsFromString("ADU").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build enigma: %v", err)
+	}
+	return machine
+}
+
+func TestASCIIMarksPath(t *testing.T) {
+	machine := newMachine(t)
+	want, _ := machine.Clone().EncryptChar('H')
+
+	var buf bytes.Buffer
+	if err := ASCII(&buf, machine, 'H'); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "H -> "+string(want)) {
+		t.Errorf("missing summary for H -> %c:\n%s", want, out)
+	}
+	if !strings.Contains(out, "   K\n") || !strings.Contains(out, "   L\n") {
+		t.Errorf("expected key and lamp marks:\n%s", out)
+	}
+	if !strings.Contains(out, "reflector UKW-B") {
+		t.Errorf("expected reflector stage line:\n%s", out)
+	}
+
+	// rendering must not step the machine
+	if positions := machine.GetRotorPositions(); positions[0] != 0 {
+		t.Errorf("machine was stepped by rendering: %v", positions)
+	}
+}
+
+func TestSVGIsWellFormed(t *testing.T) {
+	machine := newMachine(t)
+
+	for _, key := range []rune{0, 'a'} {
+		var buf bytes.Buffer
+		if err := SVG(&buf, machine, key); err != nil {
+			t.Fatalf("render failed: %v", err)
+		}
+
+		decoder := xml.NewDecoder(&buf)
+		for {
+			_, err := decoder.Token()
+			if err != nil {
+				if err.Error() != "EOF" {
+					t.Fatalf("invalid SVG for key %q: %v", key, err)
+				}
+				break
+			}
+		}
+	}
+
+	if err := SVG(&bytes.Buffer{}, machine, '?'); err == nil {
+		t.Errorf("expected error for invalid key")
+	}
+}
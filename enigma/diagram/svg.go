@@ -0,0 +1,135 @@
+package diagram
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// sizes of the SVG drawing in pixels
+const (
+	svgMargin      = 20
+	svgHeader      = 80 // room for labels and details above the contacts
+	svgRowHeight   = 16
+	svgColumnWidth = 60 // width of a component box
+	svgGap         = 50 // horizontal space between components
+	svgContact     = 3  // radius of a contact
+)
+
+// colors of the signal path
+const (
+	svgForwardColor = "#d62728"
+	svgReturnColor  = "#1f77b4"
+)
+
+// SVG writes an SVG diagram of the machine to w. If key is not 0 the path of its signal is
+// drawn in red on the way to the reflector and in blue on the way back to the lamp.
+func SVG(w io.Writer, m *enigma.Enigma, key rune) error {
+	l, err := newLayout(m, key)
+	if err != nil {
+		return err
+	}
+
+	width := 2*svgMargin + len(l.columns)*svgColumnWidth + (len(l.columns)-1)*svgGap
+	height := 2*svgMargin + svgHeader + enigma.AlphabetSize*svgRowHeight + svgRowHeight
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="11">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+
+	// component boxes, labels and contacts
+	for i, col := range l.columns {
+		x := columnX(i)
+		top := svgMargin + svgHeader - svgRowHeight/2
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle" font-weight="bold">%s</text>`+"\n",
+			x+svgColumnWidth/2, svgMargin+12, html.EscapeString(col.label))
+		for j, detail := range col.details {
+			fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n",
+				x+svgColumnWidth/2, svgMargin+26+j*12, html.EscapeString(detail))
+		}
+
+		if i == l.keyboard {
+			for row := 0; row < enigma.AlphabetSize; row++ {
+				fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle"%s>%c</text>`+"\n",
+					x+svgColumnWidth/2, rowY(row), keyStyle(l, row), rune(row+'A'))
+			}
+			continue
+		}
+
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="#f4f4f4" stroke="#888"/>`+"\n",
+			x, top, svgColumnWidth, enigma.AlphabetSize*svgRowHeight)
+		for row := 0; row < enigma.AlphabetSize; row++ {
+			if i != 0 {
+				fmt.Fprintf(&sb, `<circle cx="%d" cy="%d" r="%d" fill="#888"/>`+"\n", x, rowY(row), svgContact)
+			}
+			fmt.Fprintf(&sb, `<circle cx="%d" cy="%d" r="%d" fill="#888"/>`+"\n", x+svgColumnWidth, rowY(row), svgContact)
+		}
+	}
+
+	// the lit signal path
+	for i := 0; i+1 < len(l.path); i++ {
+		from, to := l.path[i], l.path[i+1]
+		color := svgForwardColor
+		if to.back {
+			color = svgReturnColor
+		}
+
+		x1, y1 := pointXY(from)
+		x2, y2 := pointXY(to)
+		if from.column == 0 && to.column == 0 {
+			// the reflector turns the signal around inside its box
+			mid := columnX(0) + svgColumnWidth/2
+			fmt.Fprintf(&sb, `<polyline points="%d,%d %d,%d %d,%d %d,%d" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+				x1, y1, mid, y1, mid, y2, x2, y2, color)
+			continue
+		}
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2"/>`+"\n",
+			x1, y1, x2, y2, color)
+	}
+
+	if summary := l.summary(); summary != "" {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d">%s</text>`+"\n",
+			svgMargin, height-svgMargin, html.EscapeString(summary))
+	}
+
+	sb.WriteString("</svg>\n")
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// returns the left edge of column i
+func columnX(i int) int {
+	return svgMargin + i*(svgColumnWidth+svgGap)
+}
+
+// returns the vertical center of a contact row
+func rowY(row int) int {
+	return svgMargin + svgHeader + row*svgRowHeight
+}
+
+// returns the coordinates of a contact on the signal path
+func pointXY(p point) (int, int) {
+	x := columnX(p.column)
+	if p.side == right {
+		x += svgColumnWidth
+	}
+	return x, rowY(p.row)
+}
+
+// highlights the pressed key and the lit lamp on the keyboard column
+func keyStyle(l *layout, row int) string {
+	if len(l.path) == 0 {
+		return ""
+	}
+	switch {
+	case row == int(l.lamp-'A'):
+		return ` font-weight="bold" fill="` + svgReturnColor + `"`
+	case row == int(l.key-'A'):
+		return ` font-weight="bold" fill="` + svgForwardColor + `"`
+	}
+	return ""
+}
@@ -73,6 +73,28 @@ func (r *Rotor) Position() int {
 	return r.position
 }
 
+// returns the ring setting of this rotor (A=0, B=1, ..., Z=25)
+func (r *Rotor) RingSetting() int {
+	return r.ringSetting
+}
+
+// returns a copy of the turnover notch positions
+func (r *Rotor) Notches() []int {
+	return append([]int(nil), r.notches...)
+}
+
+// returns the forward wiring as a string of letters, e.g. "EKMFLGDQVZNTOWYHXUSPAIBRCJ"
+func (r *Rotor) Wiring() string {
+	return indexesToString(r.wiring[:])
+}
+
+// returns an independent copy of the rotor, including its position and ring setting
+func (r *Rotor) Clone() *Rotor {
+	clone := *r
+	clone.notches = r.Notches()
+	return &clone
+}
+
 // return true if the rotor is at a turnover notch position
 func (r *Rotor) AtNotch() bool {
 	for _, notch := range r.notches {
@@ -142,6 +164,16 @@ func NewReflector(name string, wiring string) (*Reflector, error) {
 	return ref, nil
 }
 
+// returns the reflector identifier
+func (ref *Reflector) Name() string {
+	return ref.name
+}
+
+// returns the wiring as a string of letters, e.g. "YRUHQSLDPXNGOKMIEBFZCWVJAT"
+func (ref *Reflector) Wiring() string {
+	return indexesToString(ref.wiring[:])
+}
+
 // passes a signal through the reflector
 func (ref *Reflector) Reflect(input int) int {
 	return ref.wiring[input]
@@ -203,9 +235,25 @@ func (pb *Plugboard) Forward(input int) int {
 	return pb.wiring[input]
 }
 
+// returns the connected letter pairs in alphabetical order, e.g. ["AB", "CD"]
+func (pb *Plugboard) Pairs() []string {
+	return strings.Fields(pb.connections())
+}
+
+// returns the connections in the "AB CD EF" form accepted by NewPlugboard
+func (pb *Plugboard) String() string {
+	return pb.connections()
+}
+
 //------------------- ENIGMA ----------------------------
 
 // struct for the entire Enigma machine
+//
+// An Enigma is not safe for concurrent use: every encrypted character steps the rotors.
+// Methods that only read the configuration (Rotors, Reflector, Plugboard, Clone,
+// GetRotorPositions) may run concurrently with each other, but not with EncryptChar,
+// Encrypt, Decrypt or SetRotorPositions. Give each goroutine its own machine with Clone,
+// or share a SyncEnigma.
 type Enigma struct {
 	rotors    []*Rotor
 	reflector *Reflector
@@ -223,6 +271,33 @@ func NewEnigma(rotors []*Rotor, reflector *Reflector, plugboard *Plugboard) *Eni
 	}
 }
 
+// returns copies of the rotors in machine order (rightmost first)
+// changing the returned rotors does not affect the machine
+func (e *Enigma) Rotors() []*Rotor {
+	rotors := make([]*Rotor, len(e.rotors))
+	for i, rotor := range e.rotors {
+		rotors[i] = rotor.Clone()
+	}
+	return rotors
+}
+
+// returns a copy of the reflector
+func (e *Enigma) Reflector() *Reflector {
+	ref := *e.reflector
+	return &ref
+}
+
+// returns a copy of the plugboard
+func (e *Enigma) Plugboard() *Plugboard {
+	pb := *e.plugboard
+	return &pb
+}
+
+// returns an independent copy of the machine in its current state
+func (e *Enigma) Clone() *Enigma {
+	return NewEnigma(e.Rotors(), e.Reflector(), e.Plugboard())
+}
+
 // implement stepping mechanism with double-stepping
 /*
 	Enigma logic:
@@ -257,29 +332,53 @@ func (e *Enigma) EncryptChar(char rune) (rune, error) {
 	// step rotors before encryption
 	e.stepRotors()
 
-	//convert to index
-	signal := int(char - 'A')
+	return rune(e.encipher(int(char-'A'), nil) + 'A'), nil
+}
 
+// sends a letter index through the machine at its current position without stepping,
+// every stage the signal passes is recorded in trace unless it is nil
+func (e *Enigma) encipher(signal int, trace *Trace) int {
 	//through plugboard
+	in := signal
 	signal = e.plugboard.Forward(signal)
+	if trace != nil {
+		trace.PlugboardIn = Stage{In: in, Out: signal}
+		trace.Forward = make([]RotorStage, 0, len(e.rotors))
+		trace.Backward = make([]RotorStage, 0, len(e.rotors))
+	}
 
 	//through rotors (right to left)
 	for i := 0; i < len(e.rotors); i++ {
+		in = signal
 		signal = e.rotors[i].Forward(signal)
+		if trace != nil {
+			trace.Forward = append(trace.Forward, e.rotorStage(i, in, signal))
+		}
 	}
 
 	// through reflector
+	in = signal
 	signal = e.reflector.Reflect(signal)
+	if trace != nil {
+		trace.Reflector = Stage{In: in, Out: signal}
+	}
 
 	// back through the rotors (left to right)
 	for i := len(e.rotors) - 1; i >= 0; i-- {
+		in = signal
 		signal = e.rotors[i].Backward(signal)
+		if trace != nil {
+			trace.Backward = append(trace.Backward, e.rotorStage(i, in, signal))
+		}
 	}
 
 	// through plugboard again
+	in = signal
 	signal = e.plugboard.Forward(signal)
-
-	return rune(signal + 'A'), nil
+	if trace != nil {
+		trace.PlugboardOut = Stage{In: in, Out: signal}
+	}
+	return signal
 }
 
 // encrypts a message, preserves spaces, ignores non-alphabetic chars
@@ -118,3 +118,140 @@ func TestRotorStepping(t *testing.T) {
 		}
 	}
 }
+
+func TestAccessorsReturnCopies(t *testing.T) {
+	machine, err := NewBuilder().
+		WithRotors("I", "II", "VI").
+		WithReflector("UKW-C").
+		WithPlugboard("QA CD").
+		WithRotorPositionsFromString("ABC").
+		WithRingSettingsFromString("XYZ").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build enigma: %v", err)
+	}
+
+	rotors := machine.Rotors()
+	if len(rotors) != 3 || rotors[2].Name != "VI" {
+		t.Fatalf("unexpected rotors: %v", rotors)
+	}
+	if rotors[0].RingSetting() != 23 || rotors[0].Wiring() != RotorWirings["I"] {
+		t.Errorf("unexpected rotor I settings: ring %d wiring %s", rotors[0].RingSetting(), rotors[0].Wiring())
+	}
+
+	notches := rotors[2].Notches()
+	if len(notches) != 2 || notches[0] != 25 || notches[1] != 12 {
+		t.Errorf("unexpected notches for rotor VI: %v", notches)
+	}
+
+	// mutating the copies must not affect the machine
+	notches[0] = 0
+	rotors[0].SetPosition(10)
+	machine.Plugboard().wiring[0] = 0
+	if machine.Rotors()[2].Notches()[0] != 25 {
+		t.Errorf("notches were modified through a copy")
+	}
+	if machine.GetRotorPositions()[0] != 0 {
+		t.Errorf("rotor position was modified through a copy")
+	}
+
+	if name := machine.Reflector().Name(); name != "UKW-C" {
+		t.Errorf("unexpected reflector name: %s", name)
+	}
+
+	pairs := machine.Plugboard().Pairs()
+	if len(pairs) != 2 || pairs[0] != "AQ" || pairs[1] != "CD" {
+		t.Errorf("unexpected plugboard pairs: %v", pairs)
+	}
+}
+
+func TestEnigmaClone(t *testing.T) {
+	machine, err := NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build enigma: %v", err)
+	}
+	machine.Encrypt("ABC")
+
+	clone := machine.Clone()
+	a, _ := machine.Encrypt("HELLOWORLD")
+	b, _ := clone.Encrypt("HELLOWORLD")
+	if a != b {
+		t.Errorf("clone diverged from original: %s vs %s", a, b)
+	}
+}
+
+func TestEncryptCharTrace(t *testing.T) {
+	build := func() *Enigma {
+		machine, err := NewBuilder().
+			WithRotors("III", "II", "I").
+			WithReflector("UKW-B").
+			WithPlugboard("AQ EP").
+			WithRotorPositionsFromString("ADU").
+			WithRingSettingsFromString("BCD").
+			Build()
+		if err != nil {
+			t.Fatalf("failed to build enigma: %v", err)
+		}
+		return machine
+	}
+
+	plain := build()
+	traced := build()
+
+	for _, char := range "HELLOWORLD" {
+		want, _ := plain.EncryptChar(char)
+
+		trace, err := traced.EncryptCharTrace(char)
+		if err != nil {
+			t.Fatalf("trace failed: %v", err)
+		}
+		if trace.Output != want {
+			t.Errorf("trace output %c, want %c", trace.Output, want)
+		}
+
+		// the stages must chain together
+		if trace.PlugboardIn.Out != trace.Forward[0].In ||
+			trace.Forward[len(trace.Forward)-1].Out != trace.Reflector.In ||
+			trace.Reflector.Out != trace.Backward[0].In ||
+			trace.Backward[len(trace.Backward)-1].Out != trace.PlugboardOut.In {
+			t.Errorf("trace stages do not chain: %+v", trace)
+		}
+		if trace.PositionsAfter[0] != (trace.PositionsBefore[0]+1)%AlphabetSize {
+			t.Errorf("right rotor did not step: %v -> %v", trace.PositionsBefore, trace.PositionsAfter)
+		}
+	}
+
+	if _, err := traced.EncryptCharTrace('1'); err == nil {
+		t.Errorf("expected error for invalid character")
+	}
+}
+
+func TestM4CompatibleWithM3(t *testing.T) {
+	// with its Greek wheel at A and ring A, the M4 enciphers like the M3 with the thick
+	// reflector, which let U-boats exchange messages with surface ships
+	for _, pair := range [][3]string{{"Beta", "UKW-B-thin", "UKW-B"}, {"Gamma", "UKW-C-thin", "UKW-C"}} {
+		m4, err := NewBuilder().WithRotors("I", "II", "III", pair[0]).WithReflector(pair[1]).
+			WithPlugboard("AT BL").WithRotorPositionsFromString("QEVA").Build()
+		if err != nil {
+			t.Fatalf("failed to build M4: %v", err)
+		}
+		m3, err := NewBuilder().WithRotors("I", "II", "III").WithReflector(pair[2]).
+			WithPlugboard("AT BL").WithRotorPositionsFromString("QEV").Build()
+		if err != nil {
+			t.Fatalf("failed to build M3: %v", err)
+		}
+
+		message := "UBOOTMELDETGELEITZUGINPLANQUADRAT"
+		got, _ := m4.Encrypt(message)
+		want, _ := m3.Encrypt(message)
+		if got != want {
+			t.Errorf("%s with %s: got %s, want %s", pair[0], pair[1], got, want)
+		}
+		if positions := m4.GetRotorPositions(); positions[3] != 0 {
+			t.Errorf("Greek wheel stepped to %d", positions[3])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package enigma
+
+import "fmt"
+
+// Equivalent reports whether the configuration at start enciphers every message of length
+// letters exactly like other at otherStart. Changing the ring setting and position of the
+// left rotor or the Greek wheel by the same amount never makes a difference, changing the
+// ring settings of the fast and middle rotor only once a turnover happens at another
+// letter.
+func (c Config) Equivalent(start State, other Config, otherStart State, length int) (bool, error) {
+	a, err := c.NewEnigma(start)
+	if err != nil {
+		return false, err
+	}
+	b, err := other.NewEnigma(otherStart)
+	if err != nil {
+		return false, err
+	}
+	if length < 0 {
+		return false, fmt.Errorf("invalid message length: %d", length)
+	}
+
+	for i := 0; i < length; i++ {
+		a.stepRotors()
+		b.stepRotors()
+		for in := 0; in < AlphabetSize; in++ {
+			if a.encipher(in, nil) != b.encipher(in, nil) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Canonical returns the key with the lowest ring settings that enciphers every message of
+// length letters like the configuration at start. The rings of the left rotor and the
+// Greek wheel become A, their positions move along so their wiring stays in place. The
+// rings of the fast and middle rotor are lowered as far as the turnovers within the
+// message allow, the fast rotor's first. Keys with the same wheel order, reflector and
+// plugboard that are equivalent for the length have the same canonical form, so a search
+// can skip every key that is not canonical.
+func (c Config) Canonical(start State, length int) (Config, State, error) {
+	if _, err := c.NewEnigma(start); err != nil {
+		return Config{}, State{}, err
+	}
+	// the wiring cores: position minus ring setting
+	var cores [MaxRotors]int
+	for i := 0; i < c.rotorCount; i++ {
+		cores[i] = (start.positions[i] - c.rings[i] + AlphabetSize) % AlphabetSize
+	}
+	key := func(fast, middle int) (Config, State) {
+		canonical, positions := c, start
+		for i := 0; i < c.rotorCount; i++ {
+			canonical.rings[i] = 0
+		}
+		canonical.rings[0], canonical.rings[1] = fast, middle
+		for i := 0; i < c.rotorCount; i++ {
+			positions.positions[i] = (cores[i] + canonical.rings[i]) % AlphabetSize
+		}
+		return canonical, positions
+	}
+
+	for fast := 0; fast < AlphabetSize; fast++ {
+		for middle := 0; middle < AlphabetSize; middle++ {
+			candidate, candidateStart := key(fast, middle)
+			if c.sameCores(start, candidate, candidateStart, length) {
+				return candidate, candidateStart, nil
+			}
+		}
+	}
+	// not reached, the original rings are among the candidates
+	return Config{}, State{}, fmt.Errorf("no canonical form found")
+}
+
+// reports whether two configurations with the same rotors turn their wiring cores the same
+// way for length key presses. Only the notches of the fast and middle rotor matter.
+func (c Config) sameCores(start State, other Config, otherStart State, length int) bool {
+	a, err := c.NewEnigma(start)
+	if err != nil {
+		return false
+	}
+	b, err := other.NewEnigma(otherStart)
+	if err != nil {
+		return false
+	}
+
+	for i := 0; i < length; i++ {
+		a.stepRotors()
+		b.stepRotors()
+		for r := 0; r < 3; r++ {
+			if (a.rotors[r].position-c.rings[r]-b.rotors[r].position+other.rings[r])%AlphabetSize != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package enigma
+
+import "testing"
+
+func TestEquivalentLeftRing(t *testing.T) {
+	a, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "AB CD", []int{3, 7, 0})
+	b, _ := a.WithRingSettings(3, 7, 10)
+	start, _ := StateFromString("QEV")
+	// moving the left ring and position together keeps its core
+	moved, _ := StateFromString("QEF")
+
+	if ok, err := a.Equivalent(start, b, moved, 2000); err != nil || !ok {
+		t.Errorf("left ring offset should be equivalent: %v %v", ok, err)
+	}
+	if ok, _ := a.Equivalent(start, b, start, 10); ok {
+		t.Errorf("a different left core should not be equivalent")
+	}
+}
+
+func TestEquivalentDependsOnLength(t *testing.T) {
+	// ring and position of the fast rotor moved together: the core is the same but the
+	// turnover comes at another letter
+	a, _ := NewConfig([]string{"I", "II", "III"}, "UKW-B", "", []int{0, 0, 0})
+	b, _ := a.WithRingSettings(5, 0, 0)
+	start, _ := StateFromString("AAA")
+	shifted, _ := StateFromString("FAA")
+
+	// rotor I turns over from Q to R, after 16 letters from A and 11 from F
+	if ok, _ := a.Equivalent(start, b, shifted, 10); !ok {
+		t.Errorf("expected equivalence before the first turnover")
+	}
+	if ok, _ := a.Equivalent(start, b, shifted, 20); ok {
+		t.Errorf("expected a difference after the turnover")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	cfg, _ := NewConfig([]string{"III", "II", "I", "Beta"}, "UKW-B-thin", "AZ", []int{4, 9, 17, 8})
+	start, _ := StateFromString("KCMT")
+
+	for _, length := range []int{5, 40, 500} {
+		canonical, canonicalStart, err := cfg.Canonical(start, length)
+		if err != nil {
+			t.Fatalf("canonical failed: %v", err)
+		}
+		rings := canonical.RingSettings()
+		if rings[2] != 0 || rings[3] != 0 {
+			t.Errorf("length %d: left and Greek rings should be A, got %v", length, rings)
+		}
+		if ok, _ := cfg.Equivalent(start, canonical, canonicalStart, length); !ok {
+			t.Errorf("length %d: canonical form %s at %s is not equivalent", length, canonical, canonicalStart)
+		}
+
+		// an equivalent key has the same canonical form
+		other, _ := cfg.WithRingSettings(4, 9, 0, 0)
+		otherStart, _ := StateFromString("KCVL")
+		again, againStart, _ := other.Canonical(otherStart, length)
+		if again != canonical || againStart != canonicalStart {
+			t.Errorf("length %d: equivalent keys have different canonical forms", length)
+		}
+	}
+
+	// short messages cannot see the fast ring at all
+	short, _, _ := cfg.Canonical(start, 5)
+	if rings := short.RingSettings(); rings[0] != 0 {
+		t.Errorf("expected fast ring A for a 5 letter message, got %v", rings)
+	}
+}
@@ -13,18 +13,24 @@ var (
 		"VI":   "JPGVOUMFYQBENHZRDKASXLICTW",
 		"VII":  "NZJHGRCXMYSWBOUFAIVLPEKQDT",
 		"VIII": "FKQHTLXOCBJSPDZRAMEWNIUYGV",
+
+		// Greek wheels of the naval M4, placed left of the three rotors next to a thin reflector
+		"Beta":  "LEYJVCNIXWPBQMDRTAKZGFUHOS",
+		"Gamma": "FSOKANUERHMBTIYCWLQPZXVGJD",
 	}
 
 	//RotorNotches -> turnover notch positions for each rotor
 	RotorNotches = map[string]string{
-		"I":    "Q",  // Turnover from Q to R
-		"II":   "E",  // Turnover from E to F
-		"III":  "V",  // Turnover from V to W
-		"IV":   "J",  // Turnover from J to K
-		"V":    "Z",  // Turnover from Z to A
-		"VI":   "ZM", // Two turnover positions
-		"VII":  "ZM", // Two turnover positions
-		"VIII": "ZM", // Two turnover positions
+		"I":     "Q",  // Turnover from Q to R
+		"II":    "E",  // Turnover from E to F
+		"III":   "V",  // Turnover from V to W
+		"IV":    "J",  // Turnover from J to K
+		"V":     "Z",  // Turnover from Z to A
+		"VI":    "ZM", // Two turnover positions
+		"VII":   "ZM", // Two turnover positions
+		"VIII":  "ZM", // Two turnover positions
+		"Beta":  "",   // Greek wheels never step
+		"Gamma": "",
 	}
 
 	// ReflectorWirings contains the historical reflector configurations
@@ -32,6 +38,10 @@ var (
 		"UKW-A": "EJMZALYXVBWFCRQUONTSPIKHGD",
 		"UKW-B": "YRUHQSLDPXNGOKMIEBFZCWVJAT",
 		"UKW-C": "FVPJIAOYEDRZXWGCTKUQSBNMHL",
+
+		// thin reflectors of the M4, used together with a Greek wheel
+		"UKW-B-thin": "ENKQAUYWJICOPBLMDXZVFTHRGS",
+		"UKW-C-thin": "RDOBJNTKVEHMLFCWZAXGYIPSUQ",
 	}
 )
 
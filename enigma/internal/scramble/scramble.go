@@ -0,0 +1,84 @@
+// Package scramble computes the permutations of the rotors and the reflector of a three
+// rotor machine without plugboard, the part of the Enigma the bombe and the Polish methods
+// simulate. Positions are core positions: the rotors are built with ring setting A.
+package scramble
+
+import (
+	"fmt"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Permutation maps every input contact to the contact the signal returns on
+type Permutation [enigma.AlphabetSize]uint8
+
+// Scrambler holds the rotors of a wheel order and the reflector. It changes the rotor
+// positions while computing permutations, give each goroutine its own with Clone.
+type Scrambler struct {
+	rotors    [3]*enigma.Rotor // machine order (rightmost first)
+	reflector *enigma.Reflector
+}
+
+// creates the scrambler of a wheel order in machine order (rightmost first)
+func New(order []string, reflectorType string) (*Scrambler, error) {
+	if len(order) != 3 {
+		return nil, fmt.Errorf("wheel order %v: 3 rotors expected", order)
+	}
+	reflector, err := enigma.NewHistoricalReflector(reflectorType)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scrambler{reflector: reflector}
+	for i, rotorType := range order {
+		if s.rotors[i], err = enigma.NewHistoricalRotor(rotorType); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// returns an independent copy
+func (s *Scrambler) Clone() *Scrambler {
+	c := &Scrambler{reflector: s.reflector}
+	for i, rotor := range s.rotors {
+		c.rotors[i] = rotor.Clone()
+	}
+	return c
+}
+
+// returns the turnover notches of rotor i in machine order
+func (s *Scrambler) Notches(i int) []int {
+	return s.rotors[i].Notches()
+}
+
+// returns the permutation with the rotor cores at the given positions
+func (s *Scrambler) Permutation(right, middle, left int) Permutation {
+	s.rotors[0].SetPosition(right)
+	s.rotors[1].SetPosition(middle)
+	s.rotors[2].SetPosition(left)
+
+	var perm Permutation
+	for in := range perm {
+		signal := in
+		for _, rotor := range s.rotors {
+			signal = rotor.Forward(signal)
+		}
+		signal = s.reflector.Reflect(signal)
+		for i := len(s.rotors) - 1; i >= 0; i-- {
+			signal = s.rotors[i].Backward(signal)
+		}
+		perm[in] = uint8(signal)
+	}
+	return perm
+}
+
+// returns the permutations for all positions of the fast rotor with the middle and left
+// cores fixed, indexed by the fast rotor's core position
+func (s *Scrambler) FastPermutations(middle, left int) [enigma.AlphabetSize]Permutation {
+	var perms [enigma.AlphabetSize]Permutation
+	for right := range perms {
+		perms[right] = s.Permutation(right, middle, left)
+	}
+	return perms
+}
@@ -0,0 +1,36 @@
+package scramble
+
+import (
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestPermutationMatchesMachine(t *testing.T) {
+	s, err := New([]string{"III", "II", "I"}, "UKW-B")
+	if err != nil {
+		t.Fatalf("failed to create scrambler: %v", err)
+	}
+	perms := s.Clone().FastPermutations(3, 7)
+
+	machine, _ := enigma.NewBuilder().WithRotors("III", "II", "I").WithReflector("UKW-B").Build()
+	for right, perm := range perms {
+		for in := range perm {
+			// the machine steps the fast rotor before it enciphers, the middle rotor (D)
+			// is away from its notch (E) and the fast rotor never carries it here
+			machine.SetRotorPositions((right+enigma.AlphabetSize-1)%enigma.AlphabetSize, 3, 7)
+			if machine.GetRotorPositions()[0] == 21 {
+				continue
+			}
+			out, _ := machine.EncryptChar(rune('A' + in))
+			if int(out-'A') != int(perm[in]) {
+				t.Fatalf("fast rotor at %d: %c enciphers to %c, the scrambler gives %c",
+					right, 'A'+in, out, 'A'+perm[in])
+			}
+		}
+	}
+
+	if _, err := New([]string{"I", "II"}, "UKW-B"); err == nil {
+		t.Errorf("expected error for two rotors")
+	}
+}
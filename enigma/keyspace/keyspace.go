@@ -0,0 +1,245 @@
+package keyspace
+
+/*
+	Keyspace counts the keys of an Enigma: wheel orders, ring settings, start positions,
+	plugboards and reflectors. The total counts every setting an operator could make, the
+	effective count merges the settings that encipher every message the same way.
+
+	The left rotor of the M3 and the Greek wheel of the M4 never move anything to their
+	left, their notch has no effect. Changing the ring setting and the position of such a
+	wheel by the same amount leaves its wiring where it was, so of the 26 * 26 ring settings
+	and positions only 26 are different. The ring settings of the fast and middle rotor
+	decide when the next rotor turns over and are counted in full, although short messages
+	cannot tell all of them apart (see enigma.Config.Equivalent).
+*/
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Model is a type of Enigma machine
+type Model int
+
+const (
+	EnigmaI Model = iota // army and air force, rotors I to V
+	M3                   // navy, rotors I to VIII
+	M4                   // navy from 1942, M3 with a Greek wheel and a thin reflector
+)
+
+func (m Model) String() string {
+	switch m {
+	case EnigmaI:
+		return "Enigma I"
+	case M3:
+		return "M3"
+	case M4:
+		return "M4"
+	}
+	return fmt.Sprintf("Model(%d)", int(m))
+}
+
+// Constraints describe what is known or allowed of a key
+type Constraints struct {
+	Model Model
+
+	// rotor types the wheel order is chosen from, nil uses all rotors of the model
+	Rotors []string
+
+	// Greek wheels of the M4, nil uses Beta and Gamma
+	GreekWheels []string
+
+	// reflector types, nil uses UKW-B for the Enigma I and M3 and UKW-B-thin for the M4
+	Reflectors []string
+
+	// exact number of plugboard pairs, 0 to 13
+	PlugPairs int
+
+	// any number of pairs from 0 to 13, overrides PlugPairs
+	AnyPlugPairs bool
+
+	// the ring settings are known
+	FixedRings bool
+}
+
+// Keyspace is the number of keys under some constraints
+type Keyspace struct {
+	WheelOrders  *big.Int // including the Greek wheel of the M4
+	Reflectors   *big.Int
+	RingSettings *big.Int
+	Positions    *big.Int
+	Plugboards   *big.Int
+
+	Total     *big.Int // product of the above
+	Effective *big.Int // keys that encipher differently
+}
+
+// TotalBits returns the size of the total keyspace in bits
+func (k Keyspace) TotalBits() float64 {
+	return Bits(k.Total)
+}
+
+// EffectiveBits returns the size of the effective keyspace in bits
+func (k Keyspace) EffectiveBits() float64 {
+	return Bits(k.Effective)
+}
+
+func (k Keyspace) String() string {
+	return fmt.Sprintf("%s keys (%.1f bits), %s effective (%.1f bits)",
+		k.Total, k.TotalBits(), k.Effective, k.EffectiveBits())
+}
+
+// Bits returns the base 2 logarithm of n, 0 for n < 1
+func Bits(n *big.Int) float64 {
+	if n.Sign() <= 0 {
+		return 0
+	}
+	mantissa := new(big.Float)
+	exponent := new(big.Float).SetInt(n).MantExp(mantissa)
+	m, _ := mantissa.Float64()
+	return float64(exponent) + math.Log2(m)
+}
+
+// Count returns the keyspace of a machine under the constraints
+func Count(c Constraints) (Keyspace, error) {
+	rotors := c.Rotors
+	if rotors == nil {
+		rotors = []string{"I", "II", "III", "IV", "V"}
+		if c.Model != EnigmaI {
+			rotors = append(rotors, "VI", "VII", "VIII")
+		}
+	}
+	reflectors := c.Reflectors
+	if reflectors == nil {
+		reflectors = []string{"UKW-B"}
+		if c.Model == M4 {
+			reflectors = []string{"UKW-B-thin"}
+		}
+	}
+	var greekWheels []string
+	if c.Model == M4 {
+		greekWheels = c.GreekWheels
+		if greekWheels == nil {
+			greekWheels = []string{"Beta", "Gamma"}
+		}
+	}
+
+	switch {
+	case c.Model < EnigmaI || c.Model > M4:
+		return Keyspace{}, fmt.Errorf("unknown model %v", c.Model)
+	case len(rotors) < 3:
+		return Keyspace{}, fmt.Errorf("3 rotors needed, got %d", len(rotors))
+	case c.Model == M4 && len(greekWheels) == 0:
+		return Keyspace{}, fmt.Errorf("the M4 needs a Greek wheel")
+	case len(reflectors) == 0:
+		return Keyspace{}, fmt.Errorf("no reflector")
+	case !c.AnyPlugPairs && (c.PlugPairs < 0 || c.PlugPairs > enigma.AlphabetSize/2):
+		return Keyspace{}, fmt.Errorf("invalid number of plug pairs: %d", c.PlugPairs)
+	}
+	if err := checkWheels(rotors, true); err != nil {
+		return Keyspace{}, err
+	}
+	if err := checkWheels(greekWheels, false); err != nil {
+		return Keyspace{}, err
+	}
+	if err := checkDistinct(reflectors); err != nil {
+		return Keyspace{}, err
+	}
+	for _, reflector := range reflectors {
+		if _, err := enigma.NewHistoricalReflector(reflector); err != nil {
+			return Keyspace{}, err
+		}
+	}
+
+	wheels := 3
+	// wheels whose ring setting only turns their core: the left rotor and the Greek wheel
+	redundant := 1
+	if c.Model == M4 {
+		wheels, redundant = 4, 2
+	}
+
+	k := Keyspace{
+		WheelOrders:  permutations(len(rotors), 3),
+		Reflectors:   big.NewInt(int64(len(reflectors))),
+		RingSettings: big.NewInt(1),
+		Positions:    power(enigma.AlphabetSize, wheels),
+	}
+	if greekWheels != nil {
+		k.WheelOrders.Mul(k.WheelOrders, big.NewInt(int64(len(greekWheels))))
+	}
+	if !c.FixedRings {
+		k.RingSettings = power(enigma.AlphabetSize, wheels)
+	}
+	if c.AnyPlugPairs {
+		k.Plugboards = new(big.Int)
+		for pairs := 0; pairs <= enigma.AlphabetSize/2; pairs++ {
+			k.Plugboards.Add(k.Plugboards, Plugboards(pairs))
+		}
+	} else {
+		k.Plugboards = Plugboards(c.PlugPairs)
+	}
+
+	k.Total = new(big.Int).Mul(k.WheelOrders, k.Reflectors)
+	k.Total.Mul(k.Total, k.RingSettings)
+	k.Total.Mul(k.Total, k.Positions)
+	k.Total.Mul(k.Total, k.Plugboards)
+
+	k.Effective = new(big.Int).Set(k.Total)
+	if !c.FixedRings {
+		k.Effective.Div(k.Effective, power(enigma.AlphabetSize, redundant))
+	}
+	return k, nil
+}
+
+// Plugboards returns the number of ways to connect the given number of plug pairs:
+// 26! / ((26 - 2n)! n! 2^n)
+func Plugboards(pairs int) *big.Int {
+	if pairs < 0 || pairs > enigma.AlphabetSize/2 {
+		return new(big.Int)
+	}
+	n := new(big.Int).MulRange(int64(enigma.AlphabetSize-2*pairs+1), enigma.AlphabetSize)
+	n.Div(n, new(big.Int).MulRange(1, int64(pairs)))
+	return n.Rsh(n, uint(pairs))
+}
+
+// returns n! / (n-k)!
+func permutations(n, k int) *big.Int {
+	return new(big.Int).MulRange(int64(n-k+1), int64(n))
+}
+
+func power(base, exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(exponent)), nil)
+}
+
+// checks that the wheels exist, are distinct and step (rotors) or do not (Greek wheels)
+func checkWheels(wheels []string, stepping bool) error {
+	if err := checkDistinct(wheels); err != nil {
+		return err
+	}
+	for _, wheel := range wheels {
+		rotor, err := enigma.NewHistoricalRotor(wheel)
+		if err != nil {
+			return err
+		}
+		if hasNotches := len(rotor.Notches()) > 0; hasNotches != stepping {
+			if stepping {
+				return fmt.Errorf("%s is a Greek wheel, not a rotor", wheel)
+			}
+			return fmt.Errorf("%s is a rotor, not a Greek wheel", wheel)
+		}
+	}
+	return nil
+}
+
+func checkDistinct(names []string) error {
+	for i, name := range names {
+		if slices.Contains(names[:i], name) {
+			return fmt.Errorf("%s given twice", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package keyspace
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCountEnigmaI(t *testing.T) {
+	k, err := Count(Constraints{Model: EnigmaI, PlugPairs: 10})
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if k.WheelOrders.Int64() != 60 || k.Plugboards.Int64() != 150738274937250 {
+		t.Errorf("unexpected wheel orders %s or plugboards %s", k.WheelOrders, k.Plugboards)
+	}
+	if k.Total.String() != "2793925870508516103360000" {
+		t.Errorf("unexpected total %s", k.Total)
+	}
+	// the left ring setting adds nothing
+	if k.Effective.String() != "107458687327250619360000" {
+		t.Errorf("unexpected effective keys %s", k.Effective)
+	}
+	if math.Abs(k.EffectiveBits()-76.508) > 0.001 {
+		t.Errorf("unexpected effective bits %f", k.EffectiveBits())
+	}
+}
+
+func TestCountM4(t *testing.T) {
+	k, err := Count(Constraints{Model: M4, PlugPairs: 10})
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if k.WheelOrders.Int64() != 672 || k.Effective.String() != "31291969749695380357632000" {
+		t.Errorf("unexpected wheel orders %s or effective keys %s", k.WheelOrders, k.Effective)
+	}
+
+	fixed, _ := Count(Constraints{Model: M4, PlugPairs: 10, FixedRings: true})
+	if fixed.Total.Cmp(fixed.Effective) != 0 || fixed.RingSettings.Int64() != 1 {
+		t.Errorf("known rings leave no redundancy: %v", fixed)
+	}
+}
+
+func TestPlugboards(t *testing.T) {
+	for pairs, want := range map[int]int64{0: 1, 1: 325, 6: 100391791500, 13: 7905853580625} {
+		if got := Plugboards(pairs); got.Int64() != want {
+			t.Errorf("%d pairs: got %s, want %d", pairs, got, want)
+		}
+	}
+	k, _ := Count(Constraints{Model: EnigmaI, AnyPlugPairs: true})
+	if k.Plugboards.Int64() != 532985208200576 {
+		t.Errorf("unexpected number of plugboards with any pairs: %s", k.Plugboards)
+	}
+}
+
+func TestCountValidation(t *testing.T) {
+	for _, c := range []Constraints{
+		{Rotors: []string{"I", "II"}},
+		{Rotors: []string{"I", "II", "Beta"}},
+		{Rotors: []string{"I", "I", "II"}},
+		{Model: M4, GreekWheels: []string{"I"}},
+		{Reflectors: []string{"UKW-X"}},
+		{PlugPairs: 14},
+	} {
+		if _, err := Count(c); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package enigma
+
+/*
+	Marshaling support for the machine and its components.
+
+	Every component implements encoding.TextMarshaler and json.Marshaler (plus the
+	matching unmarshalers) so that configurations can be persisted. The serialized
+	forms include the current rotor positions, a machine saved in the middle of a
+	message continues exactly where it stopped once it is restored.
+
+	Text forms:
+		Rotor:     name:wiring:notches:ring:position   e.g. "I:EKMFLGDQVZNTOWYHXUSPAIBRCJ:Q:A:A"
+		Reflector: name:wiring                         e.g. "UKW-B:YRUHQSLDPXNGOKMIEBFZCWVJAT"
+		Plugboard: the connection pairs                e.g. "AB CD EF"
+		Enigma:    same as the JSON form
+
+	YAML libraries that honour encoding.TextMarshaler pick up the text forms.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//-------------------- Rotor -----------------------------
+
+type rotorJSON struct {
+	Name        string `json:"name"`
+	Wiring      string `json:"wiring"`
+	Notches     string `json:"notches"`
+	RingSetting int    `json:"ringSetting"`
+	Position    int    `json:"position"`
+}
+
+// returns the turnover notches as a string of letters
+func (r *Rotor) notchString() string {
+	return indexesToString(r.notches)
+}
+
+func (r *Rotor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rotorJSON{
+		Name:        r.Name,
+		Wiring:      r.Wiring(),
+		Notches:     r.notchString(),
+		RingSetting: r.ringSetting,
+		Position:    r.position,
+	})
+}
+
+func (r *Rotor) UnmarshalJSON(data []byte) error {
+	var v rotorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	restored, err := restoreRotor(v)
+	if err != nil {
+		return err
+	}
+
+	*r = *restored
+	return nil
+}
+
+func (r *Rotor) MarshalText() ([]byte, error) {
+	if strings.Contains(r.Name, ":") {
+		return nil, fmt.Errorf("rotor name must not contain ':': %s", r.Name)
+	}
+
+	text := strings.Join([]string{
+		r.Name,
+		r.Wiring(),
+		r.notchString(),
+		string(rune(r.ringSetting + 'A')),
+		string(rune(r.position + 'A')),
+	}, ":")
+	return []byte(text), nil
+}
+
+func (r *Rotor) UnmarshalText(text []byte) error {
+	parts := strings.Split(string(text), ":")
+	if len(parts) != 5 {
+		return fmt.Errorf("invalid rotor text: %q", text)
+	}
+
+	ring, err := letterToIndex(parts[3])
+	if err != nil {
+		return fmt.Errorf("invalid rotor ring setting: %w", err)
+	}
+	pos, err := letterToIndex(parts[4])
+	if err != nil {
+		return fmt.Errorf("invalid rotor position: %w", err)
+	}
+
+	restored, err := restoreRotor(rotorJSON{
+		Name:        parts[0],
+		Wiring:      parts[1],
+		Notches:     parts[2],
+		RingSetting: ring,
+		Position:    pos,
+	})
+	if err != nil {
+		return err
+	}
+
+	*r = *restored
+	return nil
+}
+
+// rebuilds a rotor from its serialized fields
+func restoreRotor(v rotorJSON) (*Rotor, error) {
+	if v.RingSetting < 0 || v.RingSetting >= AlphabetSize {
+		return nil, fmt.Errorf("invalid ring setting: %d", v.RingSetting)
+	}
+	if v.Position < 0 || v.Position >= AlphabetSize {
+		return nil, fmt.Errorf("invalid rotor position: %d", v.Position)
+	}
+
+	rotor, err := NewRotor(v.Name, v.Wiring, v.Notches)
+	if err != nil {
+		return nil, err
+	}
+	rotor.SetRingSetting(v.RingSetting)
+	rotor.SetPosition(v.Position)
+	return rotor, nil
+}
+
+// -------------- Reflector --------------------
+
+type reflectorJSON struct {
+	Name   string `json:"name"`
+	Wiring string `json:"wiring"`
+}
+
+func (ref *Reflector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(reflectorJSON{
+		Name:   ref.name,
+		Wiring: ref.Wiring(),
+	})
+}
+
+func (ref *Reflector) UnmarshalJSON(data []byte) error {
+	var v reflectorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	restored, err := NewReflector(v.Name, v.Wiring)
+	if err != nil {
+		return err
+	}
+
+	*ref = *restored
+	return nil
+}
+
+func (ref *Reflector) MarshalText() ([]byte, error) {
+	if strings.Contains(ref.name, ":") {
+		return nil, fmt.Errorf("reflector name must not contain ':': %s", ref.name)
+	}
+	return []byte(ref.name + ":" + ref.Wiring()), nil
+}
+
+func (ref *Reflector) UnmarshalText(text []byte) error {
+	name, wiring, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("invalid reflector text: %q", text)
+	}
+
+	restored, err := NewReflector(name, wiring)
+	if err != nil {
+		return err
+	}
+
+	*ref = *restored
+	return nil
+}
+
+//----------------------- Plugboard ----------------------------------------
+
+// returns the connections in the "AB CD EF" form accepted by NewPlugboard
+func (pb *Plugboard) connections() string {
+	var pairs []string
+	for i, j := range pb.wiring {
+		if i < j {
+			pairs = append(pairs, string([]rune{rune(i + 'A'), rune(j + 'A')}))
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+// the JSON form of a plugboard is its text form as a JSON string
+func (pb *Plugboard) MarshalText() ([]byte, error) {
+	return []byte(pb.connections()), nil
+}
+
+func (pb *Plugboard) UnmarshalText(text []byte) error {
+	restored, err := NewPlugboard(string(text))
+	if err != nil {
+		return err
+	}
+
+	*pb = *restored
+	return nil
+}
+
+//------------------- ENIGMA ----------------------------
+
+type enigmaJSON struct {
+	Rotors    []*Rotor   `json:"rotors"`
+	Reflector *Reflector `json:"reflector"`
+	Plugboard *Plugboard `json:"plugboard"`
+}
+
+// rotors are listed in machine order (rightmost first), each with its current position
+func (e *Enigma) MarshalJSON() ([]byte, error) {
+	return json.Marshal(enigmaJSON{
+		Rotors:    e.rotors,
+		Reflector: e.reflector,
+		Plugboard: e.plugboard,
+	})
+}
+
+func (e *Enigma) UnmarshalJSON(data []byte) error {
+	var v enigmaJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if len(v.Rotors) == 0 {
+		return fmt.Errorf("at least one rotor must be specified")
+	}
+	for i, rotor := range v.Rotors {
+		if rotor == nil {
+			return fmt.Errorf("rotor %d is missing", i)
+		}
+	}
+	if v.Reflector == nil {
+		return fmt.Errorf("reflector must be specified")
+	}
+	// the machine steps three rotors, the Greek wheel of the M4 is a fourth
+	if len(v.Rotors) < 3 || len(v.Rotors) > MaxRotors {
+		return fmt.Errorf("a machine needs 3 to %d rotors, got %d", MaxRotors, len(v.Rotors))
+	}
+
+	*e = *NewEnigma(v.Rotors, v.Reflector, v.Plugboard)
+	return nil
+}
+
+// the text form of the machine is its JSON form
+func (e *Enigma) MarshalText() ([]byte, error) {
+	return e.MarshalJSON()
+}
+
+func (e *Enigma) UnmarshalText(text []byte) error {
+	return e.UnmarshalJSON(text)
+}
+
+// ------------------- helpers ----------------------------
+
+// converts letter indexes (0-25) to a string of letters
+func indexesToString(indexes []int) string {
+	letters := make([]byte, len(indexes))
+	for i, idx := range indexes {
+		letters[i] = byte(idx + 'A')
+	}
+	return string(letters)
+}
+
+// converts a single letter (case insensitive) to its index (0-25)
+func letterToIndex(s string) (int, error) {
+	if len(s) != 1 {
+		return 0, fmt.Errorf("expected a single letter, got %q", s)
+	}
+
+	char := rune(strings.ToUpper(s)[0])
+	if char < 'A' || char > 'Z' {
+		return 0, fmt.Errorf("invalid letter: %c", char)
+	}
+	return int(char - 'A'), nil
+}
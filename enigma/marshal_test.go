@@ -0,0 +1,135 @@
+package enigma
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRotorTextRoundTrip(t *testing.T) {
+	rotor, err := NewHistoricalRotor("VI")
+	if err != nil {
+		t.Fatalf("failed to create rotor: %v", err)
+	}
+	rotor.SetRingSetting(3)
+	rotor.SetPosition(17)
+
+	text, err := rotor.MarshalText()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(text) != "VI:JPGVOUMFYQBENHZRDKASXLICTW:ZM:D:R" {
+		t.Errorf("unexpected text form: %s", text)
+	}
+
+	var restored Rotor
+	if err := restored.UnmarshalText(text); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if restored.Name != "VI" || restored.Position() != 17 || restored.ringSetting != 3 {
+		t.Errorf("restored rotor mismatch: %+v", restored)
+	}
+	for i := 0; i < AlphabetSize; i++ {
+		if restored.Forward(i) != rotor.Forward(i) {
+			t.Errorf("restored wiring mismatch for %d", i)
+		}
+	}
+}
+
+func TestPlugboardJSON(t *testing.T) {
+	pb, err := NewPlugboard("ZA dc EF")
+	if err != nil {
+		t.Fatalf("failed to create plugboard: %v", err)
+	}
+
+	data, err := json.Marshal(pb)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != `"AZ CD EF"` {
+		t.Errorf("unexpected JSON form: %s", data)
+	}
+
+	var restored Plugboard
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if restored != *pb {
+		t.Errorf("restored plugboard mismatch")
+	}
+
+	if err := json.Unmarshal([]byte(`"AB AC"`), &restored); err == nil {
+		t.Errorf("expected error for invalid plugboard")
+	}
+}
+
+func TestEnigmaJSONResumesMidMessage(t *testing.T) {
+	build := func() *Enigma {
+		machine, err := NewBuilder().
+			WithRotors("I", "II", "III").
+			WithReflector("UKW-B").
+			WithPlugboard("AB CD EF").
+			WithRotorPositionsFromString("ADU").
+			WithRingSettingsFromString("BCD").
+			Build()
+		if err != nil {
+			t.Fatalf("failed to build enigma: %v", err)
+		}
+		return machine
+	}
+
+	plaintext := "THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG"
+	want, _ := build().Encrypt(plaintext)
+
+	machine := build()
+	first, _ := machine.Encrypt(plaintext[:12])
+
+	data, err := json.Marshal(machine)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var restored Enigma
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	rest, err := restored.Encrypt(plaintext[12:])
+	if err != nil {
+		t.Fatalf("encrypt after restore failed: %v", err)
+	}
+
+	if first+rest != want {
+		t.Errorf("resumed ciphertext mismatch: got %s, want %s", first+rest, want)
+	}
+}
+
+func TestEnigmaUnmarshalRequiresReflector(t *testing.T) {
+	var machine Enigma
+	err := json.Unmarshal([]byte(`{"rotors":[{"name":"I","wiring":"EKMFLGDQVZNTOWYHXUSPAIBRCJ","notches":"Q"}]}`), &machine)
+	if err == nil {
+		t.Errorf("expected error for missing reflector")
+	}
+}
+
+func TestEnigmaUnmarshalRotorCount(t *testing.T) {
+	machine, _ := NewBuilder().WithRotors("I", "II", "III").WithReflector("UKW-B").Build()
+	data, _ := json.Marshal(machine)
+
+	var v map[string]any
+	json.Unmarshal(data, &v)
+	rotors := v["rotors"].([]any)
+	for _, count := range []int{2, 5} {
+		changed := make([]any, count)
+		for i := range changed {
+			changed[i] = rotors[i%len(rotors)]
+		}
+		v["rotors"] = changed
+		doc, _ := json.Marshal(v)
+
+		var restored Enigma
+		if err := json.Unmarshal(doc, &restored); err == nil {
+			t.Errorf("expected error for a document with %d rotors", count)
+		}
+	}
+}
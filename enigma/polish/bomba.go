@@ -0,0 +1,175 @@
+package polish
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/internal/scramble"
+)
+
+// BombaOptions control Bomba
+type BombaOptions struct {
+	// rotor types to build wheel orders from, nil uses DefaultRotors
+	Rotors []string
+
+	// explicit wheel orders in machine order (rightmost first), overrides Rotors
+	WheelOrders [][]string
+
+	// reflector type, "" uses UKW-B
+	Reflector string
+
+	// number of goroutines, 0 uses runtime.GOMAXPROCS(0)
+	Workers int
+}
+
+// BombaStop is a wheel order and ring setting at which the bomba stopped
+type BombaStop struct {
+	Rotors    []string // machine order (rightmost first)
+	Reflector string
+	Rings     []int // machine order
+}
+
+// returns a Builder for the stop, positions and plugboard are still unknown
+func (s BombaStop) Builder() *enigma.Builder {
+	return ringBuilder(s.Rotors, s.Reflector, s.Rings)
+}
+
+func (s BombaStop) String() string {
+	return fmt.Sprintf("%s %s rings %s", strings.Join(s.Rotors, " "), s.Reflector, ringLetters(s.Rings))
+}
+
+// BombaResult is the outcome of Bomba
+type BombaResult struct {
+	Stops []BombaStop
+
+	// wheel orders that were not run because the middle rotor turns over within one of
+	// the indicators
+	Skipped [][]string
+}
+
+// Bomba simulates Rejewski's cryptologic bomb of 1938. It needs three females with the
+// same repeated letter, ideally one at each place. Every female drives a pair of Enigmas
+// set to its ground setting, the second one three steps ahead of the first. The six
+// machines turn together through all ring settings, which moves each pair to the core
+// position of its female, and the bomba stops where the repeated letter comes back through
+// both machines of every pair, as it did when the operator enciphered his key twice.
+//
+// Like the historical machine it only works if the repeated letter is not steckered and
+// assumes that only the fast rotor moves within the indicators. A wheel order for which a
+// female's ground setting turns over the middle rotor is skipped.
+func Bomba(ctx context.Context, females []Female, opts BombaOptions) (BombaResult, error) {
+	if len(females) != 3 {
+		return BombaResult{}, fmt.Errorf("3 females expected, got %d", len(females))
+	}
+	for _, f := range females {
+		if len(f.Ground.Positions()) != 3 || f.Place < 0 || f.Place > 2 || f.Letter < 'A' || f.Letter > 'Z' {
+			return BombaResult{}, fmt.Errorf("invalid female: %v", f)
+		}
+		if f.Letter != females[0].Letter {
+			return BombaResult{}, fmt.Errorf("females repeat different letters: %c and %c", females[0].Letter, f.Letter)
+		}
+	}
+
+	reflector := opts.Reflector
+	if reflector == "" {
+		reflector = "UKW-B"
+	}
+	orders, scramblers, err := wheelOrderScramblers(opts.Rotors, opts.WheelOrders, reflector)
+	if err != nil {
+		return BombaResult{}, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	stops := make([][]BombaStop, len(orders))
+	skipped := make([]bool, len(orders))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(orders)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rings, ok := runBomba(scramblers[i], females)
+				skipped[i] = !ok
+				for _, r := range rings {
+					stops[i] = append(stops[i], BombaStop{
+						Rotors:    append([]string(nil), orders[i]...),
+						Reflector: reflector,
+						Rings:     r,
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range orders {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return BombaResult{}, err
+	}
+
+	var result BombaResult
+	for i := range orders {
+		result.Stops = append(result.Stops, stops[i]...)
+		if skipped[i] {
+			result.Skipped = append(result.Skipped, append([]string(nil), orders[i]...))
+		}
+	}
+	return result, nil
+}
+
+// turns the six machines of one wheel order through all ring settings and returns those
+// at which the bomba stops, false if a female turns the middle rotor over
+func runBomba(s *scrambler, females []Female) ([][]int, bool) {
+	var grounds [3][]int
+	for k, f := range females {
+		grounds[k] = f.Ground.Positions()
+		if s.turnsOver(grounds[k][0], grounds[k][1]) {
+			return nil, false
+		}
+	}
+	letter := females[0].Letter - 'A'
+
+	var stops [][]int
+	var perms [3][enigma.AlphabetSize]scramble.Permutation
+	for left := 0; left < enigma.AlphabetSize; left++ {
+		for middle := 0; middle < enigma.AlphabetSize; middle++ {
+			for k := range females {
+				perms[k] = s.FastPermutations(
+					(grounds[k][1]-middle+enigma.AlphabetSize)%enigma.AlphabetSize,
+					(grounds[k][2]-left+enigma.AlphabetSize)%enigma.AlphabetSize,
+				)
+			}
+
+		rings:
+			for right := 0; right < enigma.AlphabetSize; right++ {
+				for k, f := range females {
+					core := grounds[k][0] - right + enigma.AlphabetSize
+					first := &perms[k][(core+f.Place+1)%enigma.AlphabetSize]
+					second := &perms[k][(core+f.Place+4)%enigma.AlphabetSize]
+					if second[first[letter]] != letter {
+						continue rings
+					}
+				}
+				stops = append(stops, []int{right, middle, left})
+			}
+		}
+	}
+	return stops, true
+}
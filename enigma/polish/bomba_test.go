@@ -0,0 +1,79 @@
+package polish
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestBombaStopsAtRings(t *testing.T) {
+	rotors := []string{"II", "III", "I"}
+	rings := []int{5, 19, 12}
+	plugs := "AZ BY CX DW EV FU"
+	cfg, _ := enigma.NewConfig(rotors, "UKW-B", plugs, rings)
+
+	// collect indicators until an unsteckered letter was repeated at all three places,
+	// the bomba cannot use females where the middle rotor moves
+	s, _ := newScrambler(rotors, "UKW-B")
+	rng := rand.New(rand.NewPCG(5, 5))
+	byLetter := make(map[byte][3][]Female)
+	var chosen []Female
+	for i := 0; i < 5000 && chosen == nil; i++ {
+		ground, _ := enigma.NewState(rng.IntN(26), rng.IntN(26), rng.IntN(26))
+		indicator := doubledIndicators(t, cfg, ground, 1, uint64(i))[0]
+		females, err := FindFemales([]string{ground.String() + indicator})
+		if err != nil {
+			t.Fatalf("failed to find females: %v", err)
+		}
+		for _, f := range females {
+			positions := f.Ground.Positions()
+			if strings.IndexByte(plugs, f.Letter) >= 0 || s.turnsOver(positions[0], positions[1]) {
+				continue
+			}
+			places := byLetter[f.Letter]
+			places[f.Place] = append(places[f.Place], f)
+			byLetter[f.Letter] = places
+			if len(places[0]) > 0 && len(places[1]) > 0 && len(places[2]) > 0 {
+				chosen = []Female{places[0][0], places[1][0], places[2][0]}
+				break
+			}
+		}
+	}
+	if chosen == nil {
+		t.Fatalf("no letter repeated at all places")
+	}
+
+	result, err := Bomba(context.Background(), chosen, BombaOptions{})
+	if err != nil {
+		t.Fatalf("bomba failed: %v", err)
+	}
+	found := slices.ContainsFunc(result.Stops, func(s BombaStop) bool {
+		return slices.Equal(s.Rotors, rotors) && slices.Equal(s.Rings, rings)
+	})
+	if !found {
+		t.Errorf("rings %v not among the stops %v", rings, result.Stops)
+	}
+	// about one random stop per wheel order
+	if len(result.Stops) > 30 {
+		t.Errorf("too many stops: %d", len(result.Stops))
+	}
+}
+
+func TestBombaValidation(t *testing.T) {
+	ground, _ := enigma.StateFromString("ABC")
+	if _, err := Bomba(context.Background(), []Female{{Ground: ground, Letter: 'A'}}, BombaOptions{}); err == nil {
+		t.Errorf("expected error for too few females")
+	}
+	females := []Female{
+		{Ground: ground, Place: 0, Letter: 'A'},
+		{Ground: ground, Place: 1, Letter: 'A'},
+		{Ground: ground, Place: 2, Letter: 'B'},
+	}
+	if _, err := Bomba(context.Background(), females, BombaOptions{}); err == nil {
+		t.Errorf("expected error for different letters")
+	}
+}
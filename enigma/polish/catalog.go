@@ -0,0 +1,285 @@
+package polish
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/attack"
+	"github.com/ErenCanYildirim/enigma_go/enigma/internal/scramble"
+)
+
+// Characteristic is the cycle structure of the products AD, BE and CF of a day's key,
+// Rejewski's fingerprint of a wheel order and ground setting. Because the plugboard only
+// conjugates these products, it does not change their cycle lengths.
+//
+// A is the permutation enciphering the first letter of an indicator, D the fourth and so
+// on. AD maps the first letter of an indicator to its fourth, BE the second to the fifth
+// and CF the third to the sixth. The cycle lengths are sorted longest first.
+type Characteristic struct {
+	AD, BE, CF []int
+}
+
+// formats the characteristic, e.g. "13 13 / 10 10 2 2 1 1 / 9 9 4 4"
+func (c Characteristic) String() string {
+	parts := make([]string, 3)
+	for i, cycles := range [][]int{c.AD, c.BE, c.CF} {
+		lengths := make([]string, len(cycles))
+		for j, length := range cycles {
+			lengths[j] = strconv.Itoa(length)
+		}
+		parts[i] = strings.Join(lengths, " ")
+	}
+	return strings.Join(parts, " / ")
+}
+
+// CharacteristicFromIndicators builds the characteristic from the doubled indicators of
+// one day, e.g. "DMQVBN". Every letter has to appear in each of the first three places of
+// some indicator, which usually takes around 80 indicators; otherwise the missing letters
+// are reported.
+func CharacteristicFromIndicators(indicators []string) (Characteristic, error) {
+	parsed, err := parseIndicators(indicators)
+	if err != nil {
+		return Characteristic{}, err
+	}
+
+	var products [3][enigma.AlphabetSize]int
+	for p := range products {
+		for i := range products[p] {
+			products[p][i] = -1
+		}
+	}
+
+	for _, indicator := range parsed {
+		for p := 0; p < 3; p++ {
+			from, to := int(indicator[p]-'A'), int(indicator[p+3]-'A')
+			if products[p][from] >= 0 && products[p][from] != to {
+				return Characteristic{}, fmt.Errorf("indicator %s contradicts an earlier one: %c is followed by %c and %c",
+					indicator, 'A'+from, 'A'+products[p][from], 'A'+to)
+			}
+			products[p][from] = to
+		}
+	}
+
+	names := []string{"AD", "BE", "CF"}
+	for p := range products {
+		var missing []byte
+		for i, to := range products[p] {
+			if to < 0 {
+				missing = append(missing, byte('A'+i))
+			}
+		}
+		if len(missing) > 0 {
+			return Characteristic{}, fmt.Errorf("%s is incomplete, no indicator has %s in place %d",
+				names[p], missing, p+1)
+		}
+	}
+
+	return Characteristic{
+		AD: cycleLengths(products[0]),
+		BE: cycleLengths(products[1]),
+		CF: cycleLengths(products[2]),
+	}, nil
+}
+
+// returns the cycle lengths of a permutation, longest first
+func cycleLengths(perm [enigma.AlphabetSize]int) []int {
+	var lengths []int
+	var seen [enigma.AlphabetSize]bool
+	for start := range perm {
+		length := 0
+		for x := start; !seen[x]; x = perm[x] {
+			seen[x] = true
+			length++
+		}
+		if length > 0 {
+			lengths = append(lengths, length)
+		}
+	}
+	slices.Sort(lengths)
+	slices.Reverse(lengths)
+	return lengths
+}
+
+// returns the characteristic of the scrambler at a core position, with AD, BE and CF
+// computed from the six indicator permutations
+func characteristicOf(perms *[6]scramble.Permutation) Characteristic {
+	var c [3][]int
+	for p := 0; p < 3; p++ {
+		var product [enigma.AlphabetSize]int
+		for x := range product {
+			product[x] = int(perms[p+3][perms[p][x]])
+		}
+		c[p] = cycleLengths(product)
+	}
+	return Characteristic{AD: c[0], BE: c[1], CF: c[2]}
+}
+
+//-------------------- catalog -----------------------------
+
+// CatalogOptions control NewCatalog
+type CatalogOptions struct {
+	// rotor types to build wheel orders from, nil uses DefaultRotors
+	Rotors []string
+
+	// reflector type, "" uses UKW-A, the reflector in use when the catalog was made
+	Reflector string
+
+	// number of goroutines, 0 uses runtime.GOMAXPROCS(0)
+	Workers int
+}
+
+// CatalogEntry is a daily key with a given characteristic
+type CatalogEntry struct {
+	Rotors []string     // wheel order in machine order (rightmost first)
+	Ground enigma.State // core positions of the ground setting
+}
+
+// Catalog maps characteristics to the wheel orders and ground settings producing them,
+// like the card catalog the Polish Cipher Bureau built with the cyclometer
+type Catalog struct {
+	reflector   string
+	wheelOrders [][]string
+	entries     map[string][]catalogKey
+}
+
+// a compact catalog entry
+type catalogKey struct {
+	order               uint8
+	right, middle, left uint8
+}
+
+// NewCatalog computes the characteristic of every wheel order and ground setting
+func NewCatalog(ctx context.Context, opts CatalogOptions) (*Catalog, error) {
+	rotors := opts.Rotors
+	if rotors == nil {
+		rotors = DefaultRotors
+	}
+	reflector := opts.Reflector
+	if reflector == "" {
+		reflector = "UKW-A"
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	orders := attack.WheelOrders(rotors, 3)
+	if len(orders) > 256 {
+		return nil, fmt.Errorf("too many wheel orders: %d", len(orders))
+	}
+	scramblers := make([]*scrambler, len(orders))
+	for i, order := range orders {
+		s, err := newScrambler(order, reflector)
+		if err != nil {
+			return nil, err
+		}
+		scramblers[i] = s
+	}
+
+	catalog := &Catalog{
+		reflector:   reflector,
+		wheelOrders: orders,
+		entries:     make(map[string][]catalogKey),
+	}
+
+	// a unit is a wheel order with a fixed left rotor
+	type unit struct{ order, left int }
+	jobs := make(chan unit)
+	var mu sync.Mutex // guards catalog.entries
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			local := make([]*scrambler, len(scramblers))
+			for i, s := range scramblers {
+				local[i] = s.clone()
+			}
+
+			for u := range jobs {
+				found := make(map[string][]catalogKey)
+				for middle := 0; middle < enigma.AlphabetSize; middle++ {
+					for right := 0; right < enigma.AlphabetSize; right++ {
+						perms := local[u.order].indicatorPermutations(right, middle, u.left)
+						key := characteristicOf(&perms).String()
+						found[key] = append(found[key], catalogKey{
+							order: uint8(u.order), right: uint8(right), middle: uint8(middle), left: uint8(u.left),
+						})
+					}
+				}
+
+				mu.Lock()
+				for key, entries := range found {
+					catalog.entries[key] = append(catalog.entries[key], entries...)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for order := range orders {
+		for left := 0; left < enigma.AlphabetSize; left++ {
+			select {
+			case jobs <- unit{order, left}:
+			case <-ctx.Done():
+				err = ctx.Err()
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// the order of the workers must not show in the results
+	for _, entries := range catalog.entries {
+		slices.SortFunc(entries, func(a, b catalogKey) int {
+			return compareKeys(a, b)
+		})
+	}
+	return catalog, nil
+}
+
+// orders entries by wheel order, then left, middle and right position
+func compareKeys(a, b catalogKey) int {
+	return slices.Compare(
+		[]uint8{a.order, a.left, a.middle, a.right},
+		[]uint8{b.order, b.left, b.middle, b.right},
+	)
+}
+
+// returns the wheel orders and ground settings with the characteristic
+func (c *Catalog) Lookup(characteristic Characteristic) []CatalogEntry {
+	keys := c.entries[characteristic.String()]
+	entries := make([]CatalogEntry, len(keys))
+	for i, key := range keys {
+		ground, _ := enigma.NewState(int(key.right), int(key.middle), int(key.left))
+		entries[i] = CatalogEntry{
+			Rotors: append([]string(nil), c.wheelOrders[key.order]...),
+			Ground: ground,
+		}
+	}
+	return entries
+}
+
+// returns the number of distinct characteristics in the catalog
+func (c *Catalog) Size() int {
+	return len(c.entries)
+}
+
+// returns the reflector the catalog was computed for
+func (c *Catalog) Reflector() string {
+	return c.reflector
+}
@@ -0,0 +1,71 @@
+package polish
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestCatalogFindsDailyKey(t *testing.T) {
+	cfg, _ := enigma.NewConfig([]string{"II", "I", "III"}, "UKW-A", "AR GK OX BM DZ", []int{0, 4, 7})
+	// with ring E and H the middle and left cores are at P-E=L and T-H=M
+	ground, _ := enigma.StateFromString("KPT")
+	indicators := doubledIndicators(t, cfg, ground, 150, 1)
+
+	characteristic, err := CharacteristicFromIndicators(indicators)
+	if err != nil {
+		t.Fatalf("failed to build characteristic: %v", err)
+	}
+	for _, cycles := range [][]int{characteristic.AD, characteristic.BE, characteristic.CF} {
+		sum := 0
+		for _, length := range cycles {
+			sum += length
+		}
+		if sum != enigma.AlphabetSize {
+			t.Errorf("cycle lengths %v do not add up to 26", cycles)
+		}
+	}
+
+	catalog, err := NewCatalog(context.Background(), CatalogOptions{})
+	if err != nil {
+		t.Fatalf("failed to build catalog: %v", err)
+	}
+	if catalog.Size() < 1000 {
+		t.Errorf("expected many distinct characteristics, got %d", catalog.Size())
+	}
+
+	core, _ := enigma.StateFromString("KLM")
+	entries := catalog.Lookup(characteristic)
+	found := slices.ContainsFunc(entries, func(e CatalogEntry) bool {
+		return slices.Equal(e.Rotors, []string{"II", "I", "III"}) && e.Ground == core
+	})
+	if !found {
+		t.Errorf("daily key not among the %d entries for %s", len(entries), characteristic)
+	}
+}
+
+func TestCharacteristicFromIndicatorsErrors(t *testing.T) {
+	if _, err := CharacteristicFromIndicators([]string{"ABCDE"}); err == nil {
+		t.Errorf("expected error for a short indicator")
+	}
+	if _, err := CharacteristicFromIndicators([]string{"ABCDEF", "AXXBXX"}); err == nil {
+		t.Errorf("expected error for contradicting indicators")
+	}
+	if _, err := CharacteristicFromIndicators([]string{"ABCDEF"}); err == nil {
+		t.Errorf("expected error for an incomplete characteristic")
+	}
+}
+
+func TestCycleLengths(t *testing.T) {
+	var perm [enigma.AlphabetSize]int
+	for i := range perm {
+		perm[i] = i
+	}
+	perm[0], perm[1], perm[2] = 1, 2, 0
+	lengths := cycleLengths(perm)
+	if lengths[0] != 3 || len(lengths) != 24 {
+		t.Errorf("expected a 3-cycle and 23 fixed points, got %v", lengths)
+	}
+}
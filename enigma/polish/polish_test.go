@@ -0,0 +1,33 @@
+package polish
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// enciphers doubled random message keys with a daily key at its ground setting
+func doubledIndicators(t *testing.T, cfg enigma.Config, ground enigma.State, count int, seed uint64) []string {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(seed, seed))
+	machine, err := cfg.NewEnigma(ground)
+	if err != nil {
+		t.Fatalf("failed to create machine: %v", err)
+	}
+
+	indicators := make([]string, count)
+	for i := range indicators {
+		key := make([]byte, 3)
+		for j := range key {
+			key[j] = byte('A' + rng.IntN(enigma.AlphabetSize))
+		}
+		machine.SetState(ground)
+		indicator, err := machine.Encrypt(string(key) + string(key))
+		if err != nil {
+			t.Fatalf("failed to encrypt: %v", err)
+		}
+		indicators[i] = indicator
+	}
+	return indicators
+}
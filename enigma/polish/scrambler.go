@@ -0,0 +1,107 @@
+package polish
+
+/*
+	Polish contains the methods the Polish Cipher Bureau used against the Enigma before
+	the war. They all exploit the doubled message key: from 1932 to 1940 an operator
+	enciphered the three letters of his message key twice in a row, so the first and
+	fourth, second and fifth and third and sixth letter of every indicator are encryptions
+	of the same letter, six places apart in the machine.
+
+	As in Rejewski's cyclometer and the Polish sheets and bombs, only the fast rotor is
+	assumed to move while the six indicator letters are enciphered. Positions are core
+	positions: the rotor position minus its ring setting.
+*/
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/attack"
+	"github.com/ErenCanYildirim/enigma_go/enigma/internal/scramble"
+)
+
+// DefaultRotors are the three rotors of the Enigma I until December 1938
+var DefaultRotors = []string{"I", "II", "III"}
+
+// scrambler adds the doubled key of the indicators to the shared scrambler
+type scrambler struct {
+	*scramble.Scrambler
+}
+
+func newScrambler(order []string, reflectorType string) (*scrambler, error) {
+	s, err := scramble.New(order, reflectorType)
+	if err != nil {
+		return nil, err
+	}
+	return &scrambler{s}, nil
+}
+
+// returns an independent copy, positions are changed while computing permutations
+func (s *scrambler) clone() *scrambler {
+	return &scrambler{s.Clone()}
+}
+
+// reports whether the middle or left rotor moves while a doubled key is enciphered from
+// the machine positions (right, middle, left), which the methods of this package do not
+// model
+func (s *scrambler) turnsOver(right, middle int) bool {
+	// the fast rotor carries the middle one when it leaves a notch, the middle rotor
+	// steps itself and the left one when it stands at its notch
+	for i := 0; i < 6; i++ {
+		if slices.Contains(s.Notches(0), (right+i)%enigma.AlphabetSize) {
+			return true
+		}
+	}
+	return slices.Contains(s.Notches(1), middle)
+}
+
+// returns the six permutations that encipher a doubled key from the core position
+// (right, middle, left): the machine steps the fast rotor before every letter
+func (s *scrambler) indicatorPermutations(right, middle, left int) [6]scramble.Permutation {
+	var perms [6]scramble.Permutation
+	for i := range perms {
+		perms[i] = s.Permutation((right+i+1)%enigma.AlphabetSize, middle, left)
+	}
+	return perms
+}
+
+// returns the wheel orders to try, explicit ones or all orders of the rotors
+// (DefaultRotors if nil), with a scrambler for each
+func wheelOrderScramblers(rotors []string, orders [][]string, reflector string) ([][]string, []*scrambler, error) {
+	if orders == nil {
+		if rotors == nil {
+			rotors = DefaultRotors
+		}
+		orders = attack.WheelOrders(rotors, 3)
+	}
+
+	scramblers := make([]*scrambler, len(orders))
+	for i, order := range orders {
+		s, err := newScrambler(order, reflector)
+		if err != nil {
+			return nil, nil, err
+		}
+		scramblers[i] = s
+	}
+	return orders, scramblers, nil
+}
+
+// parses indicators: six letters each, white space and case are ignored
+func parseIndicators(indicators []string) ([]string, error) {
+	parsed := make([]string, len(indicators))
+	for i, indicator := range indicators {
+		indicator = strings.ToUpper(strings.Join(strings.Fields(indicator), ""))
+		if len(indicator) != 6 {
+			return nil, fmt.Errorf("indicator %q: 6 letters expected", indicators[i])
+		}
+		for _, char := range indicator {
+			if char < 'A' || char > 'Z' {
+				return nil, fmt.Errorf("indicator %q: invalid character %c", indicators[i], char)
+			}
+		}
+		parsed[i] = indicator
+	}
+	return parsed, nil
+}
@@ -0,0 +1,244 @@
+package polish
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Female is an indicator in which a letter of the doubled key was enciphered to the same
+// letter both times. It can only occur at positions where the product of the two
+// permutations has a fixed point, whatever the plugboard.
+type Female struct {
+	Ground enigma.State // the ground setting sent in clear, as machine positions
+	Place  int          // 0 for letters 1 and 4, 1 for 2 and 5, 2 for 3 and 6
+	Letter byte         // the repeated indicator letter
+}
+
+func (f Female) String() string {
+	return fmt.Sprintf("%s %c%c %d-%d", f.Ground, f.Letter, f.Letter, f.Place+1, f.Place+4)
+}
+
+// FindFemales picks the females from indicators of the procedure used from September
+// 1938: the ground setting chosen by the operator followed by the doubled message key
+// enciphered at it, e.g. "RTJ WAHWIK". An indicator with several repeated letters gives a
+// female for each.
+func FindFemales(indicators []string) ([]Female, error) {
+	var females []Female
+	for _, indicator := range indicators {
+		letters := strings.ToUpper(strings.Join(strings.Fields(indicator), ""))
+		if len(letters) != 9 {
+			return nil, fmt.Errorf("indicator %q: ground setting and 6 letters expected", indicator)
+		}
+		ground, err := enigma.StateFromString(letters[:3])
+		if err != nil {
+			return nil, fmt.Errorf("indicator %q: %w", indicator, err)
+		}
+		key, err := parseIndicators([]string{letters[3:]})
+		if err != nil {
+			return nil, err
+		}
+
+		for place := 0; place < 3; place++ {
+			if key[0][place] == key[0][place+3] {
+				females = append(females, Female{Ground: ground, Place: place, Letter: key[0][place]})
+			}
+		}
+	}
+	return females, nil
+}
+
+// SheetOptions control ZygalskiSheets
+type SheetOptions struct {
+	// rotor types to build wheel orders from, nil uses DefaultRotors
+	Rotors []string
+
+	// explicit wheel orders in machine order (rightmost first), overrides Rotors
+	WheelOrders [][]string
+
+	// reflector type, "" uses UKW-B
+	Reflector string
+
+	// number of goroutines, 0 uses runtime.GOMAXPROCS(0)
+	Workers int
+}
+
+// SheetCandidate is a wheel order and ring setting that survived all sheets
+type SheetCandidate struct {
+	Rotors    []string // machine order (rightmost first)
+	Reflector string
+	Rings     []int // machine order
+}
+
+// returns a Builder for the candidate, positions and plugboard are still unknown
+func (c SheetCandidate) Builder() *enigma.Builder {
+	return ringBuilder(c.Rotors, c.Reflector, c.Rings)
+}
+
+func (c SheetCandidate) String() string {
+	return fmt.Sprintf("%s %s rings %s", strings.Join(c.Rotors, " "), c.Reflector, ringLetters(c.Rings))
+}
+
+// ZygalskiSheets runs Zygalski's perforated sheets. For every wheel order it marks the
+// core positions where a female can occur, the holes of the sheets. Each female with
+// ground setting G says that the cores G minus the ring settings are such a position, so
+// stacking the sheets of all females shifted by their ground settings leaves only the
+// ring settings for which every female falls on a hole.
+//
+// Females whose ground setting turns the middle rotor during the indicator are skipped,
+// the sheets assume the fast rotor moves alone. About a dozen females usually leave a
+// handful of candidates.
+func ZygalskiSheets(ctx context.Context, females []Female, opts SheetOptions) ([]SheetCandidate, error) {
+	if len(females) == 0 {
+		return nil, fmt.Errorf("no females")
+	}
+	for _, f := range females {
+		if len(f.Ground.Positions()) != 3 || f.Place < 0 || f.Place > 2 {
+			return nil, fmt.Errorf("invalid female: %v", f)
+		}
+	}
+
+	reflector := opts.Reflector
+	if reflector == "" {
+		reflector = "UKW-B"
+	}
+	orders, scramblers, err := wheelOrderScramblers(opts.Rotors, opts.WheelOrders, reflector)
+	if err != nil {
+		return nil, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([][]SheetCandidate, len(orders))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(orders)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s := scramblers[i]
+				sheets := s.sheets()
+				for _, rings := range survivingRings(s, &sheets, females) {
+					results[i] = append(results[i], SheetCandidate{
+						Rotors:    append([]string(nil), orders[i]...),
+						Reflector: reflector,
+						Rings:     rings,
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range orders {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []SheetCandidate
+	for _, found := range results {
+		candidates = append(candidates, found...)
+	}
+	return candidates, nil
+}
+
+// sheets holds for each place of a female whether a core position, indexed
+// right + 26*middle + 676*left, can produce it
+type sheets [3][enigma.AlphabetSize * enigma.AlphabetSize * enigma.AlphabetSize]bool
+
+// punches the holes of all sheets of the wheel order
+func (s *scrambler) sheets() sheets {
+	var holes sheets
+	for left := 0; left < enigma.AlphabetSize; left++ {
+		for middle := 0; middle < enigma.AlphabetSize; middle++ {
+			perms := s.FastPermutations(middle, left)
+			for right := 0; right < enigma.AlphabetSize; right++ {
+				index := coreIndex(right, middle, left)
+				for place := 0; place < 3; place++ {
+					first := &perms[(right+place+1)%enigma.AlphabetSize]
+					second := &perms[(right+place+4)%enigma.AlphabetSize]
+					for x := 0; x < enigma.AlphabetSize; x++ {
+						if int(second[first[x]]) == x {
+							holes[place][index] = true
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return holes
+}
+
+// returns the ring settings (machine order) under which every usable female falls on a hole
+func survivingRings(s *scrambler, holes *sheets, females []Female) [][]int {
+	var usable []Female
+	for _, f := range females {
+		ground := f.Ground.Positions()
+		if !s.turnsOver(ground[0], ground[1]) {
+			usable = append(usable, f)
+		}
+	}
+	if len(usable) == 0 {
+		return nil
+	}
+
+	var survivors [][]int
+	for left := 0; left < enigma.AlphabetSize; left++ {
+		for middle := 0; middle < enigma.AlphabetSize; middle++ {
+		rings:
+			for right := 0; right < enigma.AlphabetSize; right++ {
+				for _, f := range usable {
+					ground := f.Ground.Positions()
+					index := coreIndex(
+						(ground[0]-right+enigma.AlphabetSize)%enigma.AlphabetSize,
+						(ground[1]-middle+enigma.AlphabetSize)%enigma.AlphabetSize,
+						(ground[2]-left+enigma.AlphabetSize)%enigma.AlphabetSize,
+					)
+					if !holes[f.Place][index] {
+						continue rings
+					}
+				}
+				survivors = append(survivors, []int{right, middle, left})
+			}
+		}
+	}
+	return survivors
+}
+
+// returns a Builder for rotors, reflector and ring settings
+func ringBuilder(rotors []string, reflector string, rings []int) *enigma.Builder {
+	return enigma.NewBuilder().
+		WithRotors(rotors...).
+		WithReflector(reflector).
+		WithRingSettings(rings...)
+}
+
+func coreIndex(right, middle, left int) int {
+	return right + enigma.AlphabetSize*(middle+enigma.AlphabetSize*left)
+}
+
+// formats ring settings as letters
+func ringLetters(rings []int) string {
+	letters := make([]byte, len(rings))
+	for i, ring := range rings {
+		letters[i] = byte('A' + ring)
+	}
+	return string(letters)
+}
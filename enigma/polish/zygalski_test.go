@@ -0,0 +1,76 @@
+package polish
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func TestZygalskiSheetsFindRings(t *testing.T) {
+	rotors := []string{"III", "I", "II"}
+	rings := []int{11, 2, 20}
+	cfg, _ := enigma.NewConfig(rotors, "UKW-B", "AZ BY CX DW EV FU", rings)
+
+	// every operator chose his own ground setting and sent it in clear
+	rng := rand.New(rand.NewPCG(3, 3))
+	var indicators []string
+	for i := 0; i < 200; i++ {
+		positions := []int{rng.IntN(26), rng.IntN(26), rng.IntN(26)}
+		ground, _ := enigma.NewState(positions...)
+		indicator := doubledIndicators(t, cfg, ground, 1, uint64(i))[0]
+		indicators = append(indicators, ground.String()+" "+indicator)
+	}
+
+	females, err := FindFemales(indicators)
+	if err != nil {
+		t.Fatalf("failed to find females: %v", err)
+	}
+	if len(females) < 20 {
+		t.Fatalf("expected more females, got %d", len(females))
+	}
+
+	candidates, err := ZygalskiSheets(context.Background(), females, SheetOptions{
+		WheelOrders: [][]string{rotors},
+	})
+	if err != nil {
+		t.Fatalf("sheets failed: %v", err)
+	}
+	found := slices.ContainsFunc(candidates, func(c SheetCandidate) bool {
+		return slices.Equal(c.Rings, rings)
+	})
+	if !found {
+		t.Fatalf("rings %v not among %d candidates", rings, len(candidates))
+	}
+	if len(candidates) > 10 {
+		t.Errorf("expected few candidates, got %d", len(candidates))
+	}
+
+	machine, err := candidates[0].Builder().WithRotorPositions(0, 0, 0).Build()
+	if err != nil {
+		t.Fatalf("failed to build candidate: %v", err)
+	}
+	if got := len(machine.GetRotorPositions()); got != 3 {
+		t.Errorf("expected 3 rotors, got %d", got)
+	}
+}
+
+func TestFindFemales(t *testing.T) {
+	females, err := FindFemales([]string{"GGG DMQDBN", "abc xyzxyz", "QWE ABCDEF"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ground, _ := enigma.StateFromString("GGG")
+	if len(females) != 4 || females[0] != (Female{Ground: ground, Place: 0, Letter: 'D'}) || females[1].Place != 0 || females[3].Place != 2 {
+		t.Errorf("unexpected females: %v", females)
+	}
+
+	if _, err := FindFemales([]string{"GGG DMQDB"}); err == nil {
+		t.Errorf("expected error for a short indicator")
+	}
+	if _, err := ZygalskiSheets(context.Background(), nil, SheetOptions{}); err == nil {
+		t.Errorf("expected error without females")
+	}
+}
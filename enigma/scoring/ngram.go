@@ -0,0 +1,211 @@
+package scoring
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// NGrams is a language model of letter n-grams. Its Score is Sinkov's statistic: the sum
+// of the log10 probabilities of all n-grams of the text. N-grams missing from the model
+// get a floor probability below that of the rarest known one.
+type NGrams struct {
+	n        int
+	logProbs []float64 // indexed by the n-gram read as a base 26 number
+	floor    float64
+}
+
+// MaxN is the longest n-gram a model holds, its table has 26^n entries
+const MaxN = 4
+
+// creates a model from n-gram counts, e.g. {"TH": 1200, "HE": 1100}
+func NewNGrams(n int, counts map[string]int) (*NGrams, error) {
+	if n < 1 || n > MaxN {
+		return nil, fmt.Errorf("n-gram length must be between 1 and %d, got %d", MaxN, n)
+	}
+
+	size := 1
+	for i := 0; i < n; i++ {
+		size *= enigma.AlphabetSize
+	}
+	model := &NGrams{n: n, logProbs: make([]float64, size)}
+
+	raw := make([]int, size)
+	total := 0
+	for gram, count := range counts {
+		gram = strings.ToUpper(gram)
+		if len(gram) != n || letters(gram) != gram {
+			return nil, fmt.Errorf("invalid %d-gram: %q", n, gram)
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("negative count for %s", gram)
+		}
+		raw[model.index(letterIndexes(gram))] += count
+		total += count
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no n-grams counted")
+	}
+
+	model.floor = math.Log10(0.1 / float64(total))
+	for i, count := range raw {
+		if count == 0 {
+			model.logProbs[i] = model.floor
+		} else {
+			model.logProbs[i] = math.Log10(float64(count) / float64(total))
+		}
+	}
+	return model, nil
+}
+
+// counts the n-grams of a sample text and creates a model from them
+func NGramsFromText(n int, text string) (*NGrams, error) {
+	indexes := letterIndexes(text)
+	counts := map[string]int{}
+	for i := 0; i+n <= len(indexes); i++ {
+		gram := make([]byte, n)
+		for j := range gram {
+			gram[j] = byte('A' + indexes[i+j])
+		}
+		counts[string(gram)]++
+	}
+	return NewNGrams(n, counts)
+}
+
+// LoadNGrams reads a frequency table with one n-gram and its count per line, separated by
+// white space, e.g. "TION 13168375". Empty lines and lines starting with # are skipped.
+// All n-grams must have the same length.
+func LoadNGrams(r io.Reader) (*NGrams, error) {
+	counts := map[string]int{}
+	n := 0
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an n-gram and a count", line)
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid count: %w", line, err)
+		}
+		if n == 0 {
+			n = len(fields[0])
+		}
+		if len(fields[0]) != n {
+			return nil, fmt.Errorf("line %d: expected a %d-gram, got %q", line, n, fields[0])
+		}
+		counts[fields[0]] += count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewNGrams(n, counts)
+}
+
+// returns the n-gram length
+func (m *NGrams) N() int {
+	return m.n
+}
+
+// returns the log10 probability of an n-gram like "ENI"
+func (m *NGrams) LogProb(gram string) float64 {
+	indexes := letterIndexes(gram)
+	if len(indexes) != m.n {
+		return m.floor
+	}
+	return m.logProbs[m.index(indexes)]
+}
+
+// Score returns the sum of the log10 probabilities of all n-grams of the text
+func (m *NGrams) Score(text string) float64 {
+	indexes := letterIndexes(text)
+	if len(indexes) < m.n {
+		return 0
+	}
+
+	// roll the base 26 index along the text
+	size := len(m.logProbs)
+	index := m.index(indexes[:m.n-1])
+	score := 0.0
+	for i := m.n - 1; i < len(indexes); i++ {
+		index = (index*enigma.AlphabetSize + indexes[i]) % size
+		score += m.logProbs[index]
+	}
+	return score
+}
+
+// reads letter indexes as a base 26 number
+func (m *NGrams) index(indexes []int) int {
+	index := 0
+	for _, letter := range indexes {
+		index = index*enigma.AlphabetSize + letter
+	}
+	return index
+}
+
+//-------------------- embedded models -----------------------------
+
+//go:embed data
+var data embed.FS
+
+var (
+	modelsMu sync.Mutex
+	models   = map[string]*NGrams{}
+)
+
+// names of the embedded tables by n-gram length
+var tableNames = map[int]string{1: "monograms", 2: "bigrams", 3: "trigrams", 4: "quadgrams"}
+
+// German returns the model of the embedded German n-gram counts, n from 1 to 4.
+// Umlauts are written AE, OE, UE and SZ as SS, as Enigma operators did.
+func German(n int) (*NGrams, error) {
+	return embedded("german", n)
+}
+
+// English returns the model of the embedded English n-gram counts, n from 1 to 4
+func English(n int) (*NGrams, error) {
+	return embedded("english", n)
+}
+
+// loads an embedded frequency table once and keeps the model
+func embedded(language string, n int) (*NGrams, error) {
+	name, ok := tableNames[n]
+	if !ok {
+		return nil, fmt.Errorf("n-gram length must be between 1 and %d, got %d", MaxN, n)
+	}
+	key := language + "_" + name
+
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	if model, ok := models[key]; ok {
+		return model, nil
+	}
+
+	table, err := data.Open("data/" + key + ".txt")
+	if err != nil {
+		return nil, err
+	}
+	defer table.Close()
+
+	model, err := LoadNGrams(table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	models[key] = model
+	return model, nil
+}
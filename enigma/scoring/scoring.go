@@ -0,0 +1,95 @@
+package scoring
+
+/*
+	Scoring rates how much a text looks like natural language, the fitness measure every
+	automated Enigma solver needs.
+
+	A Scorer returns a higher value for a better text. Scorers accept anything a machine
+	returns from Decrypt: letters are upper-cased and everything else is skipped. Two kinds
+	are provided:
+
+		IoC            index of coincidence, independent of the language and of any
+		               substitution, useful while the plugboard is still unknown
+		NGrams         Sinkov's log-likelihood of the text's n-grams (bigrams, trigrams,
+		               quadgrams) under a language model
+
+	German and English models are built from n-gram count tables embedded in the package,
+	counted from a few million letters of documentation. Other frequency tables can be
+	loaded with LoadNGrams.
+*/
+
+import (
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// Scorer rates a text, higher is better
+type Scorer interface {
+	Score(text string) float64
+}
+
+// ScorerFunc adapts a function to the Scorer interface
+type ScorerFunc func(text string) float64
+
+func (f ScorerFunc) Score(text string) float64 {
+	return f(text)
+}
+
+// IoC scores texts by their index of coincidence
+var IoC Scorer = ScorerFunc(IndexOfCoincidence)
+
+// IndexOfCoincidence returns the probability that two letters drawn from the text are the
+// same. German text is near 0.076, English near 0.066 and random text near 0.038.
+func IndexOfCoincidence(text string) float64 {
+	var counts [enigma.AlphabetSize]int
+	n := 0
+	for i := 0; i < len(text); i++ {
+		if index, ok := letterIndex(text[i]); ok {
+			counts[index]++
+			n++
+		}
+	}
+	if n < 2 {
+		return 0
+	}
+
+	sum := 0
+	for _, count := range counts {
+		sum += count * (count - 1)
+	}
+	return float64(sum) / float64(n*(n-1))
+}
+
+// returns the alphabet index of an ASCII letter in either case
+func letterIndex(char byte) (int, bool) {
+	switch {
+	case char >= 'A' && char <= 'Z':
+		return int(char - 'A'), true
+	case char >= 'a' && char <= 'z':
+		return int(char - 'a'), true
+	default:
+		return 0, false
+	}
+}
+
+// returns the letters of s as alphabet indexes
+func letterIndexes(s string) []int {
+	indexes := make([]int, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if index, ok := letterIndex(s[i]); ok {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// returns the letters of s in upper case, everything else is dropped
+func letters(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, index := range letterIndexes(s) {
+		sb.WriteByte(byte('A' + index))
+	}
+	return sb.String()
+}
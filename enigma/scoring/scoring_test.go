@@ -0,0 +1,97 @@
+package scoring
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const germanSample = "DIEWETTERVORHERSAGEFUERDASSEEGEBIETBISKAYAWINDAUSSUEDWESTMITSTAERKEFUENF"
+
+func TestIndexOfCoincidence(t *testing.T) {
+	if ioc := IndexOfCoincidence("aaaa"); ioc != 1 {
+		t.Errorf("expected 1 for a single letter, got %v", ioc)
+	}
+	if ioc := IndexOfCoincidence("ABC DEF"); ioc != 0 {
+		t.Errorf("expected 0 for distinct letters, got %v", ioc)
+	}
+	if ioc := IndexOfCoincidence("A"); ioc != 0 {
+		t.Errorf("expected 0 for a single letter, got %v", ioc)
+	}
+	if IoC.Score(germanSample) != IndexOfCoincidence(germanSample) {
+		t.Errorf("IoC scorer differs from IndexOfCoincidence")
+	}
+}
+
+func TestNGramsFromText(t *testing.T) {
+	model, err := NGramsFromText(2, "abab")
+	if err != nil {
+		t.Fatalf("failed to build model: %v", err)
+	}
+
+	// AB twice, BA once
+	if got, want := model.LogProb("AB"), math.Log10(2.0/3); math.Abs(got-want) > 1e-9 {
+		t.Errorf("LogProb(AB) = %v, want %v", got, want)
+	}
+	if model.LogProb("ZZ") >= model.LogProb("BA") {
+		t.Errorf("unknown bigram should score below known ones")
+	}
+	if got, want := model.Score("A-B-A"), math.Log10(2.0/3)+math.Log10(1.0/3); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Score = %v, want %v", got, want)
+	}
+}
+
+func TestLoadNGrams(t *testing.T) {
+	table := "# trigrams\nTHE 10\nAND 5\n\nING 5\n"
+	model, err := LoadNGrams(strings.NewReader(table))
+	if err != nil {
+		t.Fatalf("failed to load table: %v", err)
+	}
+	if model.N() != 3 {
+		t.Errorf("expected trigrams, got n=%d", model.N())
+	}
+	if got, want := model.LogProb("the"), math.Log10(0.5); math.Abs(got-want) > 1e-9 {
+		t.Errorf("LogProb(THE) = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"THE 10\nAN 5\n", "THE x\n", "THE\n", "T1E 4\n", ""} {
+		if _, err := LoadNGrams(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for table %q", bad)
+		}
+	}
+}
+
+func TestEmbeddedModels(t *testing.T) {
+	for n := 1; n <= 4; n++ {
+		german, err := German(n)
+		if err != nil {
+			t.Fatalf("German(%d): %v", n, err)
+		}
+		english, err := English(n)
+		if err != nil {
+			t.Fatalf("English(%d): %v", n, err)
+		}
+
+		// each model prefers its own language over random letters
+		random := "QXZJVKWPYFBGMQXZJVKWPYFBGMQXZJVKWPYFBGMQXZJVKWPYFBGMQXZJVKWPYFBGMQXZJVKW"
+		if german.Score(germanSample) <= german.Score(random) {
+			t.Errorf("German %d-grams do not prefer German text", n)
+		}
+		englishSample := "THEWEATHERFORECASTFORTHESEAAREAOFBISCAYWINDFROMTHESOUTHWESTFORCEFIVE"
+		if english.Score(englishSample) <= english.Score(random[:len(englishSample)]) {
+			t.Errorf("English %d-grams do not prefer English text", n)
+		}
+	}
+
+	a, _ := German(3)
+	b, _ := German(3)
+	if a != b {
+		t.Errorf("embedded models should be built once")
+	}
+	if _, err := German(5); err == nil {
+		t.Errorf("expected error for 5-grams")
+	}
+	if _, err := NewNGrams(5, map[string]int{"ABCDE": 1}); err == nil {
+		t.Errorf("expected error for a 5-gram table")
+	}
+}
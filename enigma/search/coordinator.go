@@ -0,0 +1,318 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Checkpoint is the state of a search as saved to disk
+type Checkpoint struct {
+	Job     Job      `json:"job"`
+	Done    []int    `json:"done"` // IDs of the finished units, ascending
+	Results []Result `json:"results"`
+}
+
+// LoadCheckpoint reads a checkpoint file
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// writes the checkpoint to a temporary file first, so a crash never leaves half a file
+func (c Checkpoint) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Coordinator hands out the units of a job and collects their results. Units given to a
+// worker whose connection breaks are handed out again.
+type Coordinator struct {
+	path string
+
+	mu        sync.Mutex // guards everything below
+	job       Job
+	done      []bool
+	doneCount int
+	leased    map[int]int // unit ID to the connection working on it
+	results   []Result
+	finished  chan struct{}
+	saveErr   error
+}
+
+// NewCoordinator creates a coordinator for the job that saves its progress to the
+// checkpoint file at path. If the file exists and holds the same job the search resumes
+// from it, a different job is an error.
+func NewCoordinator(job Job, path string) (*Coordinator, error) {
+	job, err := job.normalize()
+	if err != nil {
+		return nil, err
+	}
+	c := &Coordinator{
+		path:     path,
+		job:      job,
+		done:     make([]bool, job.unitCount()),
+		leased:   make(map[int]int),
+		finished: make(chan struct{}),
+	}
+
+	checkpoint, err := LoadCheckpoint(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		saved, err := checkpoint.Job.normalize()
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+		}
+		if !reflect.DeepEqual(saved, job) {
+			return nil, fmt.Errorf("checkpoint %s belongs to a different job", path)
+		}
+		for _, id := range checkpoint.Done {
+			if id < 0 || id >= len(c.done) {
+				return nil, fmt.Errorf("checkpoint %s: invalid unit %d", path, id)
+			}
+			if !c.done[id] {
+				c.done[id] = true
+				c.doneCount++
+			}
+		}
+		c.results = merge(nil, checkpoint.Results, job.Keep)
+	}
+
+	if c.doneCount == len(c.done) {
+		close(c.finished)
+	}
+	return c, nil
+}
+
+// Resume creates a coordinator for the job saved in the checkpoint file at path
+func Resume(path string) (*Coordinator, error) {
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCoordinator(checkpoint.Job, path)
+}
+
+// Serve accepts workers on the listener until the job is finished and every worker has
+// disconnected, or ctx is cancelled. The listener is closed when Serve returns.
+func (c *Coordinator) Serve(ctx context.Context, listener net.Listener) error {
+	var (
+		mu    sync.Mutex // guards conns
+		conns = make(map[int]net.Conn)
+		wg    sync.WaitGroup
+	)
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-c.finished:
+		case <-ctx.Done():
+		case <-stopped:
+		}
+		listener.Close()
+
+		// workers leave on their own once they are told the job is done
+		select {
+		case <-ctx.Done():
+		case <-stopped:
+			return
+		}
+		mu.Lock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for id := 1; ; id++ {
+		conn, err := listener.Accept()
+		if err != nil {
+			break
+		}
+		mu.Lock()
+		conns[id] = conn
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			server := rpc.NewServer()
+			server.RegisterName("Coordinator", &service{c: c, conn: id})
+			server.ServeConn(conn)
+
+			mu.Lock()
+			delete(conns, id)
+			mu.Unlock()
+			c.release(id)
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-c.finished:
+		return c.saveError()
+	default:
+		return fmt.Errorf("listener closed before the job was finished")
+	}
+}
+
+// Finished is closed when every unit is done
+func (c *Coordinator) Finished() <-chan struct{} {
+	return c.finished
+}
+
+// Progress returns the number of finished units and of all units
+func (c *Coordinator) Progress() (done, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.doneCount, len(c.done)
+}
+
+// Results returns the best results found so far
+func (c *Coordinator) Results() []Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.results)
+}
+
+func (c *Coordinator) saveError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.saveErr
+}
+
+// returns the lowest unit that is neither done nor worked on
+func (c *Coordinator) next(conn int) (Unit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, done := range c.done {
+		if _, ok := c.leased[id]; !done && !ok {
+			c.leased[id] = conn
+			return c.job.unit(id), true
+		}
+	}
+	return Unit{}, false
+}
+
+// records the results of a unit and saves the checkpoint
+func (c *Coordinator) report(conn, id int, results []Result) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id < 0 || id >= len(c.done) {
+		return fmt.Errorf("invalid unit %d", id)
+	}
+	if c.leased[id] == conn {
+		delete(c.leased, id)
+	}
+	if c.done[id] {
+		return nil
+	}
+
+	c.done[id] = true
+	c.doneCount++
+	c.results = merge(c.results, results, c.job.Keep)
+
+	checkpoint := Checkpoint{Job: c.job, Results: c.results}
+	for id, done := range c.done {
+		if done {
+			checkpoint.Done = append(checkpoint.Done, id)
+		}
+	}
+	if err := checkpoint.save(c.path); err != nil && c.saveErr == nil {
+		c.saveErr = err
+	}
+
+	if c.doneCount == len(c.done) {
+		close(c.finished)
+	}
+	return nil
+}
+
+// gives the units of a closed connection back
+func (c *Coordinator) release(conn int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, owner := range c.leased {
+		if owner == conn {
+			delete(c.leased, id)
+		}
+	}
+}
+
+//-------------------- protocol -----------------------------
+
+// Assignment is the coordinator's answer to a worker asking for work
+type Assignment struct {
+	Job  Job
+	Unit Unit
+
+	Wait bool // all units are handed out, ask again later
+	Done bool // the job is finished
+}
+
+// Report carries the results of a unit from a worker
+type Report struct {
+	Unit    int
+	Results []Result
+}
+
+// service is the RPC interface of a coordinator for one connection
+type service struct {
+	c    *Coordinator
+	conn int
+}
+
+func (s *service) Next(_ int, assignment *Assignment) error {
+	select {
+	case <-s.c.finished:
+		assignment.Done = true
+		return nil
+	default:
+	}
+
+	unit, ok := s.c.next(s.conn)
+	assignment.Job = s.c.job
+	assignment.Unit = unit
+	assignment.Wait = !ok
+	return nil
+}
+
+func (s *service) Report(report Report, _ *bool) error {
+	return s.c.report(s.conn, report.Unit, report.Results)
+}
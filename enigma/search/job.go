@@ -0,0 +1,205 @@
+package search
+
+/*
+	Search runs exhaustive key searches that take too long for one process. A Job is split
+	into units of one wheel order and one ring setting each, which cover all start positions.
+	A Coordinator hands the units out to worker processes over a local socket, keeps the
+	best results and records its progress in a checkpoint file, so a search that is stopped
+	resumes where it was. Results are merged in a fixed order, the outcome does not depend
+	on which worker finished which unit first.
+*/
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+	"github.com/ErenCanYildirim/enigma_go/enigma/attack"
+	"github.com/ErenCanYildirim/enigma_go/enigma/scoring"
+)
+
+// Job describes a search. With a crib it finds the keys that encipher the crib to the
+// ciphertext at CribOffset, scored by the crib length; without one every start position is
+// scored by the index of coincidence of its decryption.
+type Job struct {
+	Ciphertext string `json:"ciphertext"`
+	Crib       string `json:"crib,omitempty"`
+	CribOffset int    `json:"cribOffset,omitempty"`
+
+	// rotor types to build wheel orders from, nil uses I to V
+	Rotors []string `json:"rotors,omitempty"`
+
+	// explicit wheel orders in machine order (rightmost first), overrides Rotors
+	WheelOrders [][]string `json:"wheelOrders,omitempty"`
+
+	// reflector type, "" uses UKW-B
+	Reflector string `json:"reflector,omitempty"`
+
+	// known plugboard connections, e.g. "AB CD"
+	Plugboard string `json:"plugboard,omitempty"`
+
+	// also search the ring settings of the fast and middle rotor, 676 times the work. The
+	// left ring only turns the left rotor's core, which the start positions cover.
+	SearchRings bool `json:"searchRings,omitempty"`
+
+	// number of best results kept, 0 means 10
+	Keep int `json:"keep,omitempty"`
+}
+
+// Unit is one piece of work: all start positions of a wheel order and ring setting
+type Unit struct {
+	ID     int
+	Rotors []string // machine order (rightmost first)
+	Rings  []int    // machine order
+}
+
+// Result is a key found by the search
+type Result struct {
+	Rotors    []string `json:"rotors"`
+	Reflector string   `json:"reflector"`
+	Plugboard string   `json:"plugboard,omitempty"`
+	Rings     []int    `json:"rings"`
+	Start     string   `json:"start"` // start position, e.g. "AAA"
+	Score     float64  `json:"score"`
+}
+
+// returns the configuration and start position of the result
+func (r Result) Config() (enigma.Config, enigma.State, error) {
+	cfg, err := enigma.NewConfig(r.Rotors, r.Reflector, r.Plugboard, r.Rings)
+	if err != nil {
+		return enigma.Config{}, enigma.State{}, err
+	}
+	start, err := enigma.StateFromString(r.Start)
+	return cfg, start, err
+}
+
+func (r Result) String() string {
+	cfg, _, err := r.Config()
+	if err != nil {
+		return fmt.Sprintf("invalid result: %v", err)
+	}
+	return fmt.Sprintf("%s at %s: %g", cfg, r.Start, r.Score)
+}
+
+// checks the job and fills in its defaults
+func (j Job) normalize() (Job, error) {
+	j.Ciphertext = attack.Letters(j.Ciphertext)
+	j.Crib = attack.Letters(j.Crib)
+	if j.Ciphertext == "" {
+		return Job{}, fmt.Errorf("empty ciphertext")
+	}
+	if j.Crib != "" && (j.CribOffset < 0 || j.CribOffset+len(j.Crib) > len(j.Ciphertext)) {
+		return Job{}, fmt.Errorf("crib of length %d at offset %d does not fit a ciphertext of length %d",
+			len(j.Crib), j.CribOffset, len(j.Ciphertext))
+	}
+	if j.Reflector == "" {
+		j.Reflector = "UKW-B"
+	}
+	if j.WheelOrders == nil {
+		rotors := j.Rotors
+		if rotors == nil {
+			rotors = []string{"I", "II", "III", "IV", "V"}
+		}
+		j.WheelOrders = attack.WheelOrders(rotors, 3)
+		j.Rotors = nil
+	}
+	if len(j.WheelOrders) == 0 {
+		// e.g. "wheelOrders": [] in a job file, the job would finish at once
+		return Job{}, fmt.Errorf("no wheel orders to search")
+	}
+	if j.Keep <= 0 {
+		j.Keep = 10
+	}
+
+	for _, order := range j.WheelOrders {
+		if _, err := enigma.NewConfig(order, j.Reflector, j.Plugboard, nil); err != nil {
+			return Job{}, err
+		}
+	}
+	return j, nil
+}
+
+// returns the number of units of a normalized job
+func (j Job) unitCount() int {
+	if j.SearchRings {
+		return len(j.WheelOrders) * enigma.AlphabetSize * enigma.AlphabetSize
+	}
+	return len(j.WheelOrders)
+}
+
+// returns the unit with the given ID of a normalized job
+func (j Job) unit(id int) Unit {
+	rings := 1
+	if j.SearchRings {
+		rings = enigma.AlphabetSize * enigma.AlphabetSize
+	}
+	order := j.WheelOrders[id/rings]
+	unitRings := make([]int, len(order))
+	unitRings[0] = id % rings % enigma.AlphabetSize
+	unitRings[1] = id % rings / enigma.AlphabetSize
+	return Unit{ID: id, Rotors: slices.Clone(order), Rings: unitRings}
+}
+
+// searches all start positions of a unit and returns its best results
+func (j Job) run(ctx context.Context, unit Unit, workers int) ([]Result, error) {
+	cfg, err := enigma.NewConfig(unit.Rotors, j.Reflector, j.Plugboard, unit.Rings)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := attack.Options{Workers: workers}
+	var found []attack.Result
+	if j.Crib != "" {
+		found, err = attack.KnownPlaintext(ctx, cfg, j.Crib, j.Ciphertext, j.CribOffset, opts)
+	} else {
+		found, err = attack.SearchPositions(ctx, cfg, opts, func(machine *enigma.Enigma) (float64, bool) {
+			plaintext, _ := machine.Decrypt(j.Ciphertext)
+			return scoring.IndexOfCoincidence(plaintext), true
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(found))
+	for i, r := range found {
+		results[i] = Result{
+			Rotors:    unit.Rotors,
+			Reflector: j.Reflector,
+			Plugboard: j.Plugboard,
+			Rings:     unit.Rings,
+			Start:     r.Start.String(),
+			Score:     r.Score,
+		}
+	}
+	return merge(nil, results, j.Keep), nil
+}
+
+// merges two result lists and keeps the best. Results are ordered by score, then by wheel
+// order, rings and start, so the outcome does not depend on the order of merging.
+func merge(a, b []Result, keep int) []Result {
+	merged := append(slices.Clone(a), b...)
+	slices.SortFunc(merged, compareResults)
+	if len(merged) > keep {
+		merged = merged[:keep]
+	}
+	return merged
+}
+
+func compareResults(a, b Result) int {
+	if a.Score != b.Score {
+		if a.Score > b.Score {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(strings.Join(a.Rotors, " "), strings.Join(b.Rotors, " ")); c != 0 {
+		return c
+	}
+	if c := slices.Compare(a.Rings, b.Rings); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Start, b.Start)
+}
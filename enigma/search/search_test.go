@@ -0,0 +1,135 @@
+package search
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func testJob(t *testing.T) Job {
+	t.Helper()
+	cfg, _ := enigma.NewConfig([]string{"III", "I", "II"}, "UKW-B", "AB CD", nil)
+	start, _ := enigma.StateFromString("QTM")
+	machine, _ := cfg.NewEnigma(start)
+	ciphertext, _ := machine.Encrypt("WETTERBERICHTFUERDIENORDSEE")
+	return Job{
+		Ciphertext: ciphertext,
+		Crib:       "WETTERBERICHT",
+		Rotors:     []string{"I", "II", "III"},
+		Plugboard:  "AB CD",
+	}
+}
+
+// runs a coordinator with workers until the job is done or ctx is cancelled
+func runSearch(t *testing.T, ctx context.Context, c *Coordinator, workers int, opts WorkerOptions) error {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "search.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Work(ctx, "unix", socket, opts)
+		}()
+	}
+	err = c.Serve(ctx, listener)
+	wg.Wait()
+	return err
+}
+
+func TestSearchFindsKey(t *testing.T) {
+	c, err := NewCoordinator(testJob(t), filepath.Join(t.TempDir(), "checkpoint.json"))
+	if err != nil {
+		t.Fatalf("failed to create coordinator: %v", err)
+	}
+	if err := runSearch(t, context.Background(), c, 3, WorkerOptions{Workers: 1}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	if done, total := c.Progress(); done != 6 || total != 6 {
+		t.Errorf("expected 6 of 6 units, got %d of %d", done, total)
+	}
+	results := c.Results()
+	if len(results) == 0 || results[0].Start != "QTM" || !reflect.DeepEqual(results[0].Rotors, []string{"III", "I", "II"}) {
+		t.Fatalf("key not found: %v", results)
+	}
+	if _, _, err := results[0].Config(); err != nil {
+		t.Errorf("invalid result: %v", err)
+	}
+}
+
+func TestSearchResumesFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	c, err := NewCoordinator(testJob(t), path)
+	if err != nil {
+		t.Fatalf("failed to create coordinator: %v", err)
+	}
+
+	// stop after the first unit
+	ctx, cancel := context.WithCancel(context.Background())
+	runSearch(t, ctx, c, 1, WorkerOptions{Workers: 1, Progress: func(Unit, []Result) { cancel() }})
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+	if len(checkpoint.Done) == 0 || len(checkpoint.Done) == 6 {
+		t.Fatalf("expected a partial checkpoint, got %v", checkpoint.Done)
+	}
+
+	resumed, err := Resume(path)
+	if err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if done, _ := resumed.Progress(); done != len(checkpoint.Done) {
+		t.Errorf("resumed with %d units done, want %d", done, len(checkpoint.Done))
+	}
+	if err := runSearch(t, context.Background(), resumed, 2, WorkerOptions{Workers: 1}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	// the merged results do not depend on how the work was split
+	fresh, _ := NewCoordinator(testJob(t), filepath.Join(t.TempDir(), "checkpoint.json"))
+	runSearch(t, context.Background(), fresh, 1, WorkerOptions{Workers: 1})
+	if !reflect.DeepEqual(resumed.Results(), fresh.Results()) {
+		t.Errorf("results differ:\n%v\n%v", resumed.Results(), fresh.Results())
+	}
+
+	other := testJob(t)
+	other.Crib = "WETTER"
+	if _, err := NewCoordinator(other, path); err == nil {
+		t.Errorf("expected error for a checkpoint of a different job")
+	}
+}
+
+func TestJobUnits(t *testing.T) {
+	job, err := Job{Ciphertext: "ABC", WheelOrders: [][]string{{"I", "II", "III"}}, SearchRings: true}.normalize()
+	if err != nil {
+		t.Fatalf("invalid job: %v", err)
+	}
+	if job.unitCount() != 676 {
+		t.Errorf("expected 676 units, got %d", job.unitCount())
+	}
+	if unit := job.unit(27); !reflect.DeepEqual(unit.Rings, []int{1, 1, 0}) {
+		t.Errorf("unexpected rings of unit 27: %v", unit.Rings)
+	}
+	if _, err := (Job{}).normalize(); err == nil {
+		t.Errorf("expected error for an empty ciphertext")
+	}
+	if _, err := (Job{Ciphertext: "ABC", WheelOrders: [][]string{}}).normalize(); err == nil {
+		t.Errorf("expected error for an empty list of wheel orders")
+	}
+	if _, err := (Job{Ciphertext: "ABC", Rotors: []string{"I", "II"}}).normalize(); err == nil {
+		t.Errorf("expected error for too few rotors to form a wheel order")
+	}
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"context"
+	"net/rpc"
+	"time"
+)
+
+// WorkerOptions control Work
+type WorkerOptions struct {
+	// number of goroutines searching a unit, 0 uses runtime.GOMAXPROCS(0)
+	Workers int
+
+	// time to wait before asking again when all units are handed out, 0 means a second
+	PollInterval time.Duration
+
+	// called after each finished unit
+	Progress func(unit Unit, results []Result)
+}
+
+// Work connects to a coordinator at address, e.g. a unix socket path, and searches the
+// units it hands out until the job is finished. If ctx is cancelled the unit in progress
+// is dropped and the coordinator gives it to another worker.
+func Work(ctx context.Context, network, address string, opts WorkerOptions) error {
+	client, err := rpc.Dial(network, address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	poll := opts.PollInterval
+	if poll <= 0 {
+		poll = time.Second
+	}
+
+	for {
+		var assignment Assignment
+		if err := client.Call("Coordinator.Next", 0, &assignment); err != nil {
+			return err
+		}
+		if assignment.Done {
+			return nil
+		}
+		if assignment.Wait {
+			select {
+			case <-time.After(poll):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		results, err := assignment.Job.run(ctx, assignment.Unit, opts.Workers)
+		if err != nil {
+			return err
+		}
+		report := Report{Unit: assignment.Unit.ID, Results: results}
+		if err := client.Call("Coordinator.Report", report, new(bool)); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(assignment.Unit, results)
+		}
+	}
+}
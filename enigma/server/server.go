@@ -0,0 +1,439 @@
+package server
+
+/*
+	Server exposes the Enigma machine over a JSON HTTP API.
+
+	Stateless endpoints run a message through a freshly built machine:
+
+		POST   /encrypt                 {"config": {...}, "text": "HELLO"}
+		POST   /decrypt                 {"config": {...}, "text": "MFNCZ"}
+
+	Sessions keep a machine between requests, so a client can type into it key by key:
+
+		POST   /sessions                {"config": {...}}         creates a session
+		GET    /sessions/{id}                                     reads the rotor positions
+		POST   /sessions/{id}/keys      {"text": "HEL"}           presses keys
+		PUT    /sessions/{id}/positions {"positions": "AAA"}      turns the wheels
+		DELETE /sessions/{id}                                     ends the session
+
+	Every session has its own lock, requests to different sessions never wait for each
+	other. Sessions expire after a period without requests.
+*/
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+const (
+	DefaultSessionTTL  = 30 * time.Minute
+	DefaultMaxSessions = 1000
+
+	// limit for request bodies
+	maxBodySize = 1 << 20
+)
+
+// Options configures a Server, zero values use the defaults
+type Options struct {
+	SessionTTL  time.Duration // sessions expire after this long without a request
+	MaxSessions int           // number of sessions kept at the same time
+}
+
+// MachineConfig describes a machine in requests. Rotors, rings and positions are in
+// machine order (rightmost rotor first), the order Builder.WithRotors uses.
+type MachineConfig struct {
+	Rotors    []string `json:"rotors"`
+	Reflector string   `json:"reflector"`
+	Plugboard string   `json:"plugboard,omitempty"`
+	Rings     string   `json:"rings,omitempty"`     // e.g. "AAA"
+	Positions string   `json:"positions,omitempty"` // e.g. "AAA"
+}
+
+// builds the machine described by the config
+func (c MachineConfig) build() (*enigma.Enigma, error) {
+	if len(c.Rotors) != 3 && len(c.Rotors) != enigma.MaxRotors {
+		return nil, fmt.Errorf("a machine needs 3 rotors, or %d for the M4, got %d", enigma.MaxRotors, len(c.Rotors))
+	}
+
+	builder := enigma.NewBuilder().
+		WithRotors(c.Rotors...).
+		WithReflector(c.Reflector).
+		WithPlugboard(c.Plugboard)
+
+	if c.Rings != "" {
+		builder = builder.WithRingSettingsFromString(c.Rings)
+	}
+	if c.Positions != "" {
+		builder = builder.WithRotorPositionsFromString(c.Positions)
+	}
+	return builder.Build()
+}
+
+// session is a machine kept between requests
+type session struct {
+	mu      sync.Mutex // guards machine
+	machine *enigma.Enigma
+
+	// time of the last request in Unix nanoseconds, read without the lock so expiring
+	// sessions never waits for a request in progress
+	lastUsed atomic.Int64
+}
+
+// records a request to the session at t
+func (sess *session) touch(t time.Time) {
+	sess.lastUsed.Store(t.UnixNano())
+}
+
+// returns the time of the last request
+func (sess *session) lastUsedAt() time.Time {
+	return time.Unix(0, sess.lastUsed.Load())
+}
+
+// Server is an http.Handler serving the API
+type Server struct {
+	ttl         time.Duration
+	maxSessions int
+	now         func() time.Time
+
+	mu       sync.Mutex // guards sessions
+	sessions map[string]*session
+
+	mux *http.ServeMux
+}
+
+// creates a server with the given options
+func New(opts Options) *Server {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultSessionTTL
+	}
+	if opts.MaxSessions <= 0 {
+		opts.MaxSessions = DefaultMaxSessions
+	}
+
+	s := &Server{
+		ttl:         opts.SessionTTL,
+		maxSessions: opts.MaxSessions,
+		now:         time.Now,
+		sessions:    make(map[string]*session),
+		mux:         http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /encrypt", s.handleCrypt)
+	s.mux.HandleFunc("POST /decrypt", s.handleCrypt)
+	s.mux.HandleFunc("POST /sessions", s.handleCreateSession)
+	s.mux.HandleFunc("GET /sessions/{id}", s.handleGetSession)
+	s.mux.HandleFunc("POST /sessions/{id}/keys", s.handleKeys)
+	s.mux.HandleFunc("PUT /sessions/{id}/positions", s.handleSetPositions)
+	s.mux.HandleFunc("DELETE /sessions/{id}", s.handleDeleteSession)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// removes expired sessions every interval until ctx is done
+func (s *Server) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ExpireSessions()
+		}
+	}
+}
+
+// removes all sessions that were not used within the session TTL
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, sess := range s.sessions {
+		if s.expired(sess) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// returns the number of live sessions
+func (s *Server) SessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// reports whether sess has expired, it does not take the session lock
+func (s *Server) expired(sess *session) bool {
+	return s.now().Sub(sess.lastUsedAt()) > s.ttl
+}
+
+// ------------------- stateless endpoints ----------------------------
+
+type cryptRequest struct {
+	Config MachineConfig `json:"config"`
+	Text   string        `json:"text"`
+}
+
+type cryptResponse struct {
+	Text      string `json:"text"`
+	Positions string `json:"positions"` // rotor positions after the message
+}
+
+func (s *Server) handleCrypt(w http.ResponseWriter, r *http.Request) {
+	var req cryptRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	machine, err := req.Config.build()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	text, err := machine.Encrypt(req.Text)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cryptResponse{
+		Text:      text,
+		Positions: positionString(machine),
+	})
+}
+
+// ------------------- sessions ----------------------------
+
+type createSessionRequest struct {
+	Config MachineConfig `json:"config"`
+}
+
+type sessionResponse struct {
+	ID        string    `json:"id"`
+	Positions string    `json:"positions"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type keysRequest struct {
+	Text string `json:"text"`
+}
+
+type keysResponse struct {
+	Text      string `json:"text"`
+	Positions string `json:"positions"`
+}
+
+type positionsRequest struct {
+	Positions string `json:"positions"`
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req createSessionRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	machine, err := req.Config.build()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	sess := &session{machine: machine}
+	sess.touch(s.now())
+
+	s.mu.Lock()
+	if len(s.sessions) >= s.maxSessions {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many sessions"))
+		return
+	}
+	s.sessions[id] = sess
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, s.describe(id, sess))
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	id, sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	sess.touch(s.now())
+
+	writeJSON(w, http.StatusOK, s.describe(id, sess))
+}
+
+func (s *Server) handleKeys(w http.ResponseWriter, r *http.Request) {
+	_, sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var req keysRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	sess.touch(s.now())
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	text, err := sess.machine.Encrypt(req.Text)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keysResponse{
+		Text:      text,
+		Positions: positionString(sess.machine),
+	})
+}
+
+func (s *Server) handleSetPositions(w http.ResponseWriter, r *http.Request) {
+	id, sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var req positionsRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	positions, err := parsePositions(req.Positions)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess.touch(s.now())
+	sess.mu.Lock()
+	err = sess.machine.SetRotorPositions(positions...)
+	sess.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.describe(id, sess))
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// finds the session of the request, expired sessions are removed and reported as missing
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (string, *session, bool) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	if ok && s.expired(sess) {
+		delete(s.sessions, id)
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown session: %s", id))
+		return "", nil, false
+	}
+	return id, sess, true
+}
+
+// describes a session for responses
+func (s *Server) describe(id string, sess *session) sessionResponse {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	return sessionResponse{
+		ID:        id,
+		Positions: positionString(sess.machine),
+		ExpiresAt: sess.lastUsedAt().Add(s.ttl),
+	}
+}
+
+// ------------------- helpers ----------------------------
+
+func newSessionID() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf[:]), nil
+}
+
+// returns the rotor positions as letters in machine order
+func positionString(machine *enigma.Enigma) string {
+	var sb strings.Builder
+	for _, pos := range machine.GetRotorPositions() {
+		sb.WriteRune(rune(pos + 'A'))
+	}
+	return sb.String()
+}
+
+// converts positions like "AAA" to indexes
+func parsePositions(positions string) ([]int, error) {
+	result := make([]int, len(positions))
+	for i, char := range strings.ToUpper(positions) {
+		if char < 'A' || char > 'Z' {
+			return nil, fmt.Errorf("invalid position character: %c", char)
+		}
+		result[i] = int(char - 'A')
+	}
+	return result, nil
+}
+
+// decodes the JSON body of r into v, writing an error response if that fails
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
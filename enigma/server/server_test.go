@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+var testConfig = MachineConfig{
+	Rotors:    []string{"I", "II", "III"},
+	Reflector: "UKW-B",
+	Plugboard: "AB CD",
+	Rings:     "BCD",
+	Positions: "XYZ",
+}
+
+// sends a JSON request and decodes the JSON response into out
+func do(t *testing.T, h http.Handler, method, path string, body any, out any) int {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, &buf))
+
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func expected(t *testing.T, text string) string {
+	machine, err := enigma.NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		WithPlugboard("AB CD").
+		WithRingSettingsFromString("BCD").
+		WithRotorPositionsFromString("XYZ").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build enigma: %v", err)
+	}
+	result, _ := machine.Encrypt(text)
+	return result
+}
+
+func TestEncryptEndpoint(t *testing.T) {
+	srv := New(Options{})
+
+	var resp cryptResponse
+	code := do(t, srv, "POST", "/encrypt", cryptRequest{Config: testConfig, Text: "HELLO WORLD"}, &resp)
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if resp.Text != expected(t, "HELLO WORLD") {
+		t.Errorf("ciphertext %s, want %s", resp.Text, expected(t, "HELLO WORLD"))
+	}
+
+	var plain cryptResponse
+	do(t, srv, "POST", "/decrypt", cryptRequest{Config: testConfig, Text: resp.Text}, &plain)
+	if plain.Text != "HELLO WORLD" {
+		t.Errorf("decrypted %s, want HELLO WORLD", plain.Text)
+	}
+
+	bad := testConfig
+	bad.Reflector = "UKW-Z"
+	var errResp map[string]string
+	if code := do(t, srv, "POST", "/encrypt", cryptRequest{Config: bad, Text: "A"}, &errResp); code != http.StatusBadRequest {
+		t.Errorf("expected bad request for invalid reflector, got %d", code)
+	}
+	if errResp["error"] == "" {
+		t.Errorf("expected error message")
+	}
+
+	// too few rotors must not reach the stepping of the machine
+	short := testConfig
+	short.Rotors = []string{"I"}
+	short.Rings, short.Positions = "", ""
+	if code := do(t, srv, "POST", "/encrypt", cryptRequest{Config: short, Text: "A"}, nil); code != http.StatusBadRequest {
+		t.Errorf("expected bad request for a single rotor, got %d", code)
+	}
+	if code := do(t, srv, "POST", "/sessions", createSessionRequest{Config: short}, nil); code != http.StatusBadRequest {
+		t.Errorf("expected bad request for a session with a single rotor, got %d", code)
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	srv := New(Options{})
+
+	var created sessionResponse
+	if code := do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, &created); code != http.StatusCreated {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if created.Positions != "XYZ" {
+		t.Errorf("initial positions %s, want XYZ", created.Positions)
+	}
+
+	// keys pressed in several requests continue the same message
+	var first, second keysResponse
+	do(t, srv, "POST", "/sessions/"+created.ID+"/keys", keysRequest{Text: "HEL"}, &first)
+	do(t, srv, "POST", "/sessions/"+created.ID+"/keys", keysRequest{Text: "LO"}, &second)
+	if first.Text+second.Text != expected(t, "HELLO") {
+		t.Errorf("session ciphertext %s, want %s", first.Text+second.Text, expected(t, "HELLO"))
+	}
+
+	var state sessionResponse
+	do(t, srv, "PUT", "/sessions/"+created.ID+"/positions", positionsRequest{Positions: "XYZ"}, nil)
+	do(t, srv, "GET", "/sessions/"+created.ID, nil, &state)
+	if state.Positions != "XYZ" {
+		t.Errorf("positions after reset %s, want XYZ", state.Positions)
+	}
+
+	if code := do(t, srv, "DELETE", "/sessions/"+created.ID, nil, nil); code != http.StatusNoContent {
+		t.Errorf("unexpected delete status %d", code)
+	}
+	if code := do(t, srv, "GET", "/sessions/"+created.ID, nil, nil); code != http.StatusNotFound {
+		t.Errorf("expected deleted session to be gone, got %d", code)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	srv := New(Options{SessionTTL: time.Minute, MaxSessions: 2})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+
+	var a, b sessionResponse
+	do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, &a)
+	do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, &b)
+	if code := do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("expected session limit, got %d", code)
+	}
+
+	// using a session keeps it alive
+	now = now.Add(45 * time.Second)
+	do(t, srv, "GET", "/sessions/"+a.ID, nil, nil)
+	now = now.Add(30 * time.Second)
+
+	srv.ExpireSessions()
+	if srv.SessionCount() != 1 {
+		t.Errorf("expected one live session, got %d", srv.SessionCount())
+	}
+	if code := do(t, srv, "GET", "/sessions/"+b.ID, nil, nil); code != http.StatusNotFound {
+		t.Errorf("expected expired session to be gone, got %d", code)
+	}
+	if code := do(t, srv, "GET", "/sessions/"+a.ID, nil, nil); code != http.StatusOK {
+		t.Errorf("expected used session to live, got %d", code)
+	}
+}
+
+func TestBusySessionDoesNotBlockOthers(t *testing.T) {
+	srv := New(Options{})
+
+	var a, b sessionResponse
+	do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, &a)
+	do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, &b)
+
+	// a request in progress on a holds its lock
+	srv.mu.Lock()
+	busy := srv.sessions[a.ID]
+	srv.mu.Unlock()
+	busy.mu.Lock()
+	defer busy.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.ExpireSessions()
+		do(t, srv, "POST", "/sessions/"+b.ID+"/keys", keysRequest{Text: "A"}, nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a busy session blocked the janitor or another session")
+	}
+}
+
+func TestConcurrentSessionKeys(t *testing.T) {
+	srv := New(Options{})
+
+	var created sessionResponse
+	do(t, srv, "POST", "/sessions", createSessionRequest{Config: testConfig}, &created)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				do(t, srv, "POST", "/sessions/"+created.ID+"/keys", keysRequest{Text: "A"}, nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 160 keypresses move the right rotor from X by 160 positions
+	var state sessionResponse
+	do(t, srv, "GET", "/sessions/"+created.ID, nil, &state)
+	if want := rune('A' + (23+160)%26); rune(state.Positions[0]) != want {
+		t.Errorf("right rotor at %c, want %c", state.Positions[0], want)
+	}
+}
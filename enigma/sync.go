@@ -0,0 +1,83 @@
+package enigma
+
+import "sync"
+
+// SyncEnigma is a machine that is safe for concurrent use.
+//
+// It serves two kinds of callers:
+//   - Encrypt, Decrypt, EncryptChar and the position methods share one machine state,
+//     like operators taking turns at the same machine. Calls are serialized, a message
+//     is encrypted as a whole without keys of other goroutines in between.
+//   - EncryptMessage and DecryptMessage encrypt independent messages from the
+//     configuration the SyncEnigma was created with. Each call works on its own copy,
+//     so any number of goroutines run them in parallel without waiting for each other.
+type SyncEnigma struct {
+	mu      sync.Mutex // guards machine
+	machine *Enigma
+
+	// configuration at creation time, never stepped and only read after construction
+	initial *Enigma
+}
+
+// creates a SyncEnigma from a copy of machine, later changes to machine do not affect it
+func NewSyncEnigma(machine *Enigma) *SyncEnigma {
+	return &SyncEnigma{
+		machine: machine.Clone(),
+		initial: machine.Clone(),
+	}
+}
+
+// encrypts a message on the shared machine, see Enigma.Encrypt
+func (s *SyncEnigma) Encrypt(message string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.machine.Encrypt(message)
+}
+
+// decrypts a message on the shared machine, see Enigma.Decrypt
+func (s *SyncEnigma) Decrypt(message string) (string, error) {
+	return s.Encrypt(message)
+}
+
+// encrypts a single char on the shared machine, see Enigma.EncryptChar
+func (s *SyncEnigma) EncryptChar(char rune) (rune, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.machine.EncryptChar(char)
+}
+
+// sets the rotor positions of the shared machine
+func (s *SyncEnigma) SetRotorPositions(positions ...int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.machine.SetRotorPositions(positions...)
+}
+
+// returns the current rotor positions of the shared machine
+func (s *SyncEnigma) GetRotorPositions() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.machine.GetRotorPositions()
+}
+
+// returns an independent copy of the shared machine in its current state
+func (s *SyncEnigma) Clone() *Enigma {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.machine.Clone()
+}
+
+// encrypts a message starting at the given rotor positions on a private copy of the
+// initial configuration. The shared machine is neither used nor changed.
+func (s *SyncEnigma) EncryptMessage(positions []int, message string) (string, error) {
+	machine := s.initial.Clone()
+	if err := machine.SetRotorPositions(positions...); err != nil {
+		return "", err
+	}
+	return machine.Encrypt(message)
+}
+
+// decrypts a message starting at the given rotor positions, see EncryptMessage
+func (s *SyncEnigma) DecryptMessage(positions []int, message string) (string, error) {
+	return s.EncryptMessage(positions, message)
+}
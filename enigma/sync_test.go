@@ -0,0 +1,99 @@
+package enigma
+
+import (
+	"sync"
+	"testing"
+)
+
+func newSyncTestMachine(t *testing.T) *Enigma {
+	machine, err := NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		WithPlugboard("AB CD EF").
+		WithRingSettingsFromString("BCD").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build enigma: %v", err)
+	}
+	return machine
+}
+
+func TestSyncEnigmaSharedState(t *testing.T) {
+	machine := newSyncTestMachine(t)
+	shared := NewSyncEnigma(machine)
+
+	const goroutines, messages = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				if _, err := shared.Encrypt("ABCDE"); err != nil {
+					t.Errorf("encrypt failed: %v", err)
+				}
+				shared.GetRotorPositions()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// every key must have stepped the machine exactly once
+	want := machine.Clone()
+	for i := 0; i < goroutines*messages*5; i++ {
+		want.EncryptChar('A')
+	}
+	got := shared.GetRotorPositions()
+	for i, pos := range want.GetRotorPositions() {
+		if got[i] != pos {
+			t.Fatalf("positions %v, want %v", got, want.GetRotorPositions())
+		}
+	}
+
+	// the caller's machine is not shared
+	if machine.GetRotorPositions()[0] != 0 {
+		t.Errorf("caller's machine was stepped")
+	}
+}
+
+func TestSyncEnigmaIndependentMessages(t *testing.T) {
+	shared := NewSyncEnigma(newSyncTestMachine(t))
+	plaintext := "THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG"
+
+	var wg sync.WaitGroup
+	results := make([]string, AlphabetSize)
+	for i := 0; i < AlphabetSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ciphertext, err := shared.EncryptMessage([]int{i, i, i}, plaintext)
+			if err != nil {
+				t.Errorf("encrypt failed: %v", err)
+				return
+			}
+			results[i] = ciphertext
+		}()
+	}
+	wg.Wait()
+
+	for i, ciphertext := range results {
+		machine := newSyncTestMachine(t)
+		machine.SetRotorPositions(i, i, i)
+		want, _ := machine.Encrypt(plaintext)
+		if ciphertext != want {
+			t.Errorf("message at %d: got %s, want %s", i, ciphertext, want)
+		}
+
+		decrypted, _ := shared.DecryptMessage([]int{i, i, i}, ciphertext)
+		if decrypted != plaintext {
+			t.Errorf("message at %d did not decrypt: %s", i, decrypted)
+		}
+	}
+
+	if shared.GetRotorPositions()[0] != 0 {
+		t.Errorf("independent messages stepped the shared machine")
+	}
+	if _, err := shared.EncryptMessage([]int{0}, "A"); err == nil {
+		t.Errorf("expected error for wrong number of positions")
+	}
+}
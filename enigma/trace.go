@@ -0,0 +1,65 @@
+package enigma
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stage records the contact a signal enters and leaves a component on (A=0, B=1, ..., Z=25)
+type Stage struct {
+	In  int
+	Out int
+}
+
+// RotorStage records a signal passing through a single rotor
+type RotorStage struct {
+	Stage
+	Index  int    // index of the rotor in the machine (0 = rightmost)
+	Rotor  string // rotor identifier
+	Offset int    // effective offset of the wiring, (position - ring setting) mod 26
+}
+
+// Trace is the signal path of a single keypress through the machine
+type Trace struct {
+	Input           rune
+	Output          rune
+	PositionsBefore []int        // rotor positions before stepping
+	PositionsAfter  []int        // rotor positions after stepping, used for the encryption
+	PlugboardIn     Stage        // first pass through the plugboard
+	Forward         []RotorStage // rotors from right to left
+	Reflector       Stage
+	Backward        []RotorStage // rotors from left to right
+	PlugboardOut    Stage        // second pass through the plugboard
+}
+
+// encrypts a single char like EncryptChar and records every stage the signal passes
+func (e *Enigma) EncryptCharTrace(char rune) (Trace, error) {
+	char = rune(strings.ToUpper(string(char))[0])
+
+	if char < 'A' || char > 'Z' {
+		return Trace{}, fmt.Errorf("invalid character: %c (only A-Z supported)", char)
+	}
+
+	trace := Trace{
+		Input:           char,
+		PositionsBefore: e.GetRotorPositions(),
+	}
+
+	// step rotors before encryption
+	e.stepRotors()
+	trace.PositionsAfter = e.GetRotorPositions()
+
+	trace.Output = rune(e.encipher(int(char-'A'), &trace) + 'A')
+	return trace, nil
+}
+
+// records a signal passing through rotor i
+func (e *Enigma) rotorStage(i int, in, out int) RotorStage {
+	rotor := e.rotors[i]
+	return RotorStage{
+		Stage:  Stage{In: in, Out: out},
+		Index:  i,
+		Rotor:  rotor.Name,
+		Offset: (rotor.position - rotor.ringSetting + AlphabetSize) % AlphabetSize,
+	}
+}
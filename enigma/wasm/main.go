@@ -0,0 +1,320 @@
+//go:build js && wasm
+
+package main
+
+/*
+	WebAssembly entry point exposing the Enigma machine to JavaScript.
+
+	Build it and load it with the wasm_exec.js shipped with Go:
+
+		GOOS=js GOARCH=wasm go build -o enigma.wasm ./enigma/wasm
+
+	The program registers a global "enigma" object:
+
+		enigma.configure({rotors: ["I","II","III"], reflector: "UKW-B",
+		                  plugboard: "AB CD", rings: "AAA", positions: "AAA"})
+		                                     -> {id}
+		enigma.encrypt(id, "HELLO")          -> {text, positions}
+		enigma.step(id, n)                   -> {positions}   presses n keys without reading the lamps
+		enigma.getPositions(id)              -> {positions}
+		enigma.setPositions(id, "AAA")       -> {positions}
+		enigma.trace(id, "H")                -> {trace}       signal path of a single keypress
+		enigma.release(id)                   -> {}
+
+	Rotors, rings and positions are in machine order (rightmost rotor first), the order
+	Builder.WithRotors uses. Failures return an object with an "error" message instead.
+
+	The tests run under Node with Go's wasm_exec:
+
+		PATH="$(go env GOROOT)/lib/wasm:$PATH" GOOS=js GOARCH=wasm go test ./enigma/wasm
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+// machineConfig is the configuration object passed to enigma.configure
+type machineConfig struct {
+	Rotors    []string `json:"rotors"`
+	Reflector string   `json:"reflector"`
+	Plugboard string   `json:"plugboard"`
+	Rings     string   `json:"rings"`
+	Positions string   `json:"positions"`
+}
+
+// stageJSON and traceJSON are the JavaScript form of enigma.Trace, contacts are letters
+type stageJSON struct {
+	In  string `json:"in"`
+	Out string `json:"out"`
+}
+
+type rotorStageJSON struct {
+	stageJSON
+	Index  int    `json:"index"`
+	Rotor  string `json:"rotor"`
+	Offset int    `json:"offset"`
+}
+
+type traceJSON struct {
+	Input           string           `json:"input"`
+	Output          string           `json:"output"`
+	PositionsBefore string           `json:"positionsBefore"`
+	PositionsAfter  string           `json:"positionsAfter"`
+	PlugboardIn     stageJSON        `json:"plugboardIn"`
+	Forward         []rotorStageJSON `json:"forward"`
+	Reflector       stageJSON        `json:"reflector"`
+	Backward        []rotorStageJSON `json:"backward"`
+	PlugboardOut    stageJSON        `json:"plugboardOut"`
+}
+
+// largest key count for step, the rotors of a 3 rotor machine cycle within this many keys
+const maxSteps = enigma.AlphabetSize * enigma.AlphabetSize * enigma.AlphabetSize
+
+var (
+	mu       sync.Mutex
+	machines = make(map[int]*enigma.Enigma)
+	nextID   = 1
+)
+
+func main() {
+	register()
+	// keep the program alive so JavaScript can call into it
+	select {}
+}
+
+// registers the global enigma object
+func register() {
+	api := map[string]any{
+		"configure":    wrap(configure),
+		"encrypt":      wrap(encrypt),
+		"step":         wrap(step),
+		"getPositions": wrap(getPositions),
+		"setPositions": wrap(setPositions),
+		"trace":        wrap(trace),
+		"release":      wrap(release),
+	}
+	js.Global().Set("enigma", js.ValueOf(api))
+}
+
+// turns a Go implementation into a JavaScript function, errors become {error: message}.
+// A panic is reported the same way, it would otherwise stop the Go runtime for good.
+func wrap(fn func(args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) (value any) {
+		defer func() {
+			if r := recover(); r != nil {
+				value = map[string]any{"error": fmt.Sprintf("internal error: %v", r)}
+			}
+		}()
+
+		result, err := fn(args)
+		if err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+		return toJS(result)
+	})
+}
+
+// converts a Go value to a JavaScript value by way of JSON
+func toJS(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+func configure(args []js.Value) (any, error) {
+	if len(args) != 1 || args[0].Type() != js.TypeObject {
+		return nil, errors.New("configure expects a configuration object")
+	}
+
+	var cfg machineConfig
+	data := js.Global().Get("JSON").Call("stringify", args[0]).String()
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(cfg.Rotors) != 3 && len(cfg.Rotors) != enigma.MaxRotors {
+		return nil, fmt.Errorf("a machine needs 3 rotors, or %d for the M4, got %d", enigma.MaxRotors, len(cfg.Rotors))
+	}
+
+	builder := enigma.NewBuilder().
+		WithRotors(cfg.Rotors...).
+		WithReflector(cfg.Reflector).
+		WithPlugboard(cfg.Plugboard)
+	if cfg.Rings != "" {
+		builder = builder.WithRingSettingsFromString(cfg.Rings)
+	}
+	if cfg.Positions != "" {
+		builder = builder.WithRotorPositionsFromString(cfg.Positions)
+	}
+
+	machine, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	id := nextID
+	nextID++
+	machines[id] = machine
+	return map[string]int{"id": id}, nil
+}
+
+func encrypt(args []js.Value) (any, error) {
+	machine, err := machineArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := machine.Encrypt(args[1].String())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"text": text, "positions": positions(machine)}, nil
+}
+
+func step(args []js.Value) (any, error) {
+	machine, err := machineArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	n := 1
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		n = args[1].Int()
+	}
+	if n < 0 || n > maxSteps {
+		return nil, fmt.Errorf("step count must be between 0 and %d", maxSteps)
+	}
+
+	// every key steps the rotors the same way, the lamp is ignored
+	for i := 0; i < n; i++ {
+		machine.EncryptChar('A')
+	}
+	return map[string]string{"positions": positions(machine)}, nil
+}
+
+func getPositions(args []js.Value) (any, error) {
+	machine, err := machineArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"positions": positions(machine)}, nil
+}
+
+func setPositions(args []js.Value) (any, error) {
+	machine, err := machineArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := strings.ToUpper(args[1].String())
+	values := make([]int, 0, len(letters))
+	for _, char := range letters {
+		if char < 'A' || char > 'Z' {
+			return nil, fmt.Errorf("invalid position character: %c", char)
+		}
+		values = append(values, int(char-'A'))
+	}
+
+	if err := machine.SetRotorPositions(values...); err != nil {
+		return nil, err
+	}
+	return map[string]string{"positions": positions(machine)}, nil
+}
+
+func trace(args []js.Value) (any, error) {
+	machine, err := machineArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	key := args[1].String()
+	if len(key) != 1 {
+		return nil, errors.New("trace expects a single letter")
+	}
+
+	t, err := machine.EncryptCharTrace(rune(key[0]))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]traceJSON{"trace": convertTrace(t)}, nil
+}
+
+func release(args []js.Value) (any, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return nil, errors.New("expected a machine id")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	delete(machines, args[0].Int())
+	return map[string]any{}, nil
+}
+
+// returns the machine of the id in args[0] after checking that there are enough arguments
+func machineArg(args []js.Value, count int) (*enigma.Enigma, error) {
+	if len(args) < count || args[0].Type() != js.TypeNumber {
+		return nil, fmt.Errorf("expected a machine id and %d more arguments", count-1)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	machine, ok := machines[args[0].Int()]
+	if !ok {
+		return nil, fmt.Errorf("unknown machine: %d", args[0].Int())
+	}
+	return machine, nil
+}
+
+// returns the rotor positions as letters in machine order
+func positions(machine *enigma.Enigma) string {
+	return indexLetters(machine.GetRotorPositions())
+}
+
+func indexLetters(indexes []int) string {
+	var sb strings.Builder
+	for _, idx := range indexes {
+		sb.WriteRune(rune(idx + 'A'))
+	}
+	return sb.String()
+}
+
+func convertStage(s enigma.Stage) stageJSON {
+	return stageJSON{In: string(rune(s.In + 'A')), Out: string(rune(s.Out + 'A'))}
+}
+
+func convertRotorStages(stages []enigma.RotorStage) []rotorStageJSON {
+	result := make([]rotorStageJSON, len(stages))
+	for i, s := range stages {
+		result[i] = rotorStageJSON{
+			stageJSON: convertStage(s.Stage),
+			Index:     s.Index,
+			Rotor:     s.Rotor,
+			Offset:    s.Offset,
+		}
+	}
+	return result
+}
+
+func convertTrace(t enigma.Trace) traceJSON {
+	return traceJSON{
+		Input:           string(t.Input),
+		Output:          string(t.Output),
+		PositionsBefore: indexLetters(t.PositionsBefore),
+		PositionsAfter:  indexLetters(t.PositionsAfter),
+		PlugboardIn:     convertStage(t.PlugboardIn),
+		Forward:         convertRotorStages(t.Forward),
+		Reflector:       convertStage(t.Reflector),
+		Backward:        convertRotorStages(t.Backward),
+		PlugboardOut:    convertStage(t.PlugboardOut),
+	}
+}
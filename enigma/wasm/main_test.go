@@ -0,0 +1,104 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/ErenCanYildirim/enigma_go/enigma"
+)
+
+func call(name string, args ...any) js.Value {
+	return js.Global().Get("enigma").Call(name, args...)
+}
+
+func configureTestMachine(t *testing.T) int {
+	register()
+	result := call("configure", map[string]any{
+		"rotors":    []any{"I", "II", "III"},
+		"reflector": "UKW-B",
+		"plugboard": "AB CD",
+		"positions": "XYZ",
+	})
+	if errValue := result.Get("error"); !errValue.IsUndefined() {
+		t.Fatalf("configure failed: %s", errValue.String())
+	}
+	return result.Get("id").Int()
+}
+
+func TestEncryptMatchesLibrary(t *testing.T) {
+	id := configureTestMachine(t)
+
+	machine, _ := enigma.NewBuilder().
+		WithRotors("I", "II", "III").
+		WithReflector("UKW-B").
+		WithPlugboard("AB CD").
+		WithRotorPositionsFromString("XYZ").
+		Build()
+	want, _ := machine.Encrypt("HELLO WORLD")
+
+	result := call("encrypt", id, "HELLO WORLD")
+	if got := result.Get("text").String(); got != want {
+		t.Errorf("encrypt returned %s, want %s", got, want)
+	}
+	if got := result.Get("positions").String(); got != "HYZ" {
+		t.Errorf("positions after encrypt %s, want HYZ", got)
+	}
+}
+
+func TestStepAndPositions(t *testing.T) {
+	id := configureTestMachine(t)
+
+	if got := call("step", id, 3).Get("positions").String(); got != "AYZ" {
+		t.Errorf("positions after 3 steps %s, want AYZ", got)
+	}
+	if got := call("setPositions", id, "abc").Get("positions").String(); got != "ABC" {
+		t.Errorf("positions after set %s, want ABC", got)
+	}
+	if got := call("getPositions", id).Get("positions").String(); got != "ABC" {
+		t.Errorf("getPositions returned %s, want ABC", got)
+	}
+	if errValue := call("setPositions", id, "AB").Get("error"); errValue.IsUndefined() {
+		t.Errorf("expected error for wrong number of positions")
+	}
+	if errValue := call("step", id, maxSteps+1).Get("error"); errValue.IsUndefined() {
+		t.Errorf("expected error for too many steps")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	id := configureTestMachine(t)
+
+	trace := call("trace", id, "H").Get("trace")
+	if trace.IsUndefined() {
+		t.Fatalf("trace returned no trace")
+	}
+	if trace.Get("input").String() != "H" || trace.Get("forward").Length() != 3 {
+		t.Errorf("unexpected trace input %s with %d forward stages",
+			trace.Get("input").String(), trace.Get("forward").Length())
+	}
+	if trace.Get("positionsBefore").String() != "XYZ" || trace.Get("positionsAfter").String() != "YYZ" {
+		t.Errorf("unexpected trace positions %s -> %s",
+			trace.Get("positionsBefore").String(), trace.Get("positionsAfter").String())
+	}
+}
+
+func TestErrorsAndRelease(t *testing.T) {
+	register()
+
+	result := call("configure", map[string]any{"rotors": []any{"I", "II", "IX"}, "reflector": "UKW-B"})
+	if result.Get("error").IsUndefined() {
+		t.Errorf("expected error for invalid rotor")
+	}
+	result = call("configure", map[string]any{"rotors": []any{"I"}, "reflector": "UKW-B"})
+	if result.Get("error").IsUndefined() {
+		t.Errorf("expected error for a single rotor")
+	}
+
+	id := configureTestMachine(t)
+	call("release", id)
+	if call("getPositions", id).Get("error").IsUndefined() {
+		t.Errorf("expected error for released machine")
+	}
+}